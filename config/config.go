@@ -4,15 +4,75 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/rs/zerolog/log"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// AuthModeToken authenticates with a bearer token.
+	AuthModeToken = "token"
+	// AuthModeBasic logs in with username and password and authenticates with the obtained session key.
+	AuthModeBasic = "basic"
 )
 
 type Specification struct {
-	AccessToken                      string   `json:"accessToken" split_words:"true" required:"true"`
-	ApiBaseUrl                       string   `json:"apiBaseUrl" split_words:"true" required:"true"`
-	DiscoveryAttributesExcludesAlert []string `json:"discoveryAttributesExcludesAlert" split_words:"true" required:"false"`
-	InsecureSkipVerify               bool     `json:"insecureSkipVerify" split_words:"true" default:"false"`
+	InstanceName                           string        `json:"instanceName" split_words:"true" default:"default"`
+	AuthMode                               string        `json:"authMode" split_words:"true" default:"token"`
+	AccessToken                            string        `json:"accessToken" split_words:"true" required:"false"`
+	AccessTokenFile                        string        `json:"accessTokenFile" split_words:"true" required:"false"`
+	Username                               string        `json:"username" split_words:"true" required:"false"`
+	Password                               string        `json:"password" split_words:"true" required:"false"`
+	ApiBaseUrl                             string        `json:"apiBaseUrl" split_words:"true" required:"false"`
+	WebBaseUrl                             string        `json:"webBaseUrl" split_words:"true" required:"false"`
+	DiscoveryAttributesExcludesAlert       []string      `json:"discoveryAttributesExcludesAlert" split_words:"true" required:"false"`
+	DiscoveryAttributesExcludesIndex       []string      `json:"discoveryAttributesExcludesIndex" split_words:"true" required:"false"`
+	DiscoveryAttributesExcludesSavedSearch []string      `json:"discoveryAttributesExcludesSavedSearch" split_words:"true" required:"false"`
+	InsecureSkipVerify                     bool          `json:"insecureSkipVerify" split_words:"true" default:"false"`
+	ClientCertificateFile                  string        `json:"clientCertificateFile" split_words:"true" required:"false"`
+	ClientKeyFile                          string        `json:"clientKeyFile" split_words:"true" required:"false"`
+	CaCertificateFile                      string        `json:"caCertificateFile" split_words:"true" required:"false"`
+	ProxyUrl                               string        `json:"proxyUrl" split_words:"true" required:"false"`
+	ProxyUsername                          string        `json:"proxyUsername" split_words:"true" required:"false"`
+	ProxyPassword                          string        `json:"proxyPassword" split_words:"true" required:"false"`
+	NoProxy                                []string      `json:"noProxy" split_words:"true" required:"false"`
+	MaxRetries                             int           `json:"maxRetries" split_words:"true" default:"3"`
+	RetryWaitTime                          time.Duration `json:"retryWaitTime" split_words:"true" default:"500ms"`
+	RetryMaxWaitTime                       time.Duration `json:"retryMaxWaitTime" split_words:"true" default:"5s"`
+	RateLimit                              float64       `json:"rateLimit" split_words:"true" default:"10"`
+	RateLimitBurst                         int           `json:"rateLimitBurst" split_words:"true" default:"10"`
+	MaxConcurrentRequests                  int           `json:"maxConcurrentRequests" split_words:"true" default:"10"`
+	HealthCheckInterval                    time.Duration `json:"healthCheckInterval" split_words:"true" default:"30s"`
+	HealthCheckFailureThreshold            int           `json:"healthCheckFailureThreshold" split_words:"true" default:"3"`
+	// Instances are the Splunk instances to connect to. They are configured by STEADYBIT_EXTENSION_INSTANCE_<n>_*
+	// variables, or by the variables above if there are none.
+	Instances []Instance `json:"instances" ignored:"true"`
+}
+
+type Instance struct {
+	Name                  string   `json:"name" split_words:"true" required:"true"`
+	AuthMode              string   `json:"authMode" split_words:"true" default:"token"`
+	AccessToken           string   `json:"accessToken" split_words:"true" required:"false"`
+	AccessTokenFile       string   `json:"accessTokenFile" split_words:"true" required:"false"`
+	Username              string   `json:"username" split_words:"true" required:"false"`
+	Password              string   `json:"password" split_words:"true" required:"false"`
+	ApiBaseUrl            string   `json:"apiBaseUrl" split_words:"true" required:"true"`
+	WebBaseUrl            string   `json:"webBaseUrl" split_words:"true" required:"false"`
+	InsecureSkipVerify    bool     `json:"insecureSkipVerify" split_words:"true" default:"false"`
+	ClientCertificateFile string   `json:"clientCertificateFile" split_words:"true" required:"false"`
+	ClientKeyFile         string   `json:"clientKeyFile" split_words:"true" required:"false"`
+	CaCertificateFile     string   `json:"caCertificateFile" split_words:"true" required:"false"`
+	ProxyUrl              string   `json:"proxyUrl" split_words:"true" required:"false"`
+	ProxyUsername         string   `json:"proxyUsername" split_words:"true" required:"false"`
+	ProxyPassword         string   `json:"proxyPassword" split_words:"true" required:"false"`
+	NoProxy               []string `json:"noProxy" split_words:"true" required:"false"`
 }
 
 var (
@@ -24,4 +84,182 @@ func ParseConfiguration() {
 	if err != nil {
 		log.Fatal().Err(err).Msgf("Failed to parse configuration from environment.")
 	}
+	Config.Instances, err = parseInstances(Config)
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Failed to parse configuration from environment.")
+	}
+	if err := validate(Config); err != nil {
+		log.Fatal().Err(err).Msgf("Invalid configuration.")
+	}
+}
+
+func parseInstances(spec Specification) ([]Instance, error) {
+	var instances []Instance
+	for i := 0; ; i++ {
+		prefix := fmt.Sprintf("steadybit_extension_instance_%d", i)
+		if _, ok := os.LookupEnv(strings.ToUpper(prefix + "_API_BASE_URL")); !ok {
+			break
+		}
+		var instance Instance
+		if err := envconfig.Process(prefix, &instance); err != nil {
+			return nil, err
+		}
+		instances = append(instances, instance)
+	}
+
+	if err := checkInstanceNumbering(os.Environ(), len(instances)); err != nil {
+		return nil, err
+	}
+
+	if len(instances) > 0 {
+		// the chart always sets some of the connection settings without number, so they are only logged
+		if ignored := ignoredConnectionSettings(spec); len(ignored) > 0 {
+			log.Error().Strs("settings", ignored).Msg("Connection settings without instance number are ignored, because STEADYBIT_EXTENSION_INSTANCE_0_API_BASE_URL is set.")
+		}
+		return instances, nil
+	}
+	return []Instance{
+		{
+			Name:                  spec.InstanceName,
+			AuthMode:              spec.AuthMode,
+			AccessToken:           spec.AccessToken,
+			AccessTokenFile:       spec.AccessTokenFile,
+			Username:              spec.Username,
+			Password:              spec.Password,
+			ApiBaseUrl:            spec.ApiBaseUrl,
+			WebBaseUrl:            spec.WebBaseUrl,
+			InsecureSkipVerify:    spec.InsecureSkipVerify,
+			ClientCertificateFile: spec.ClientCertificateFile,
+			ClientKeyFile:         spec.ClientKeyFile,
+			CaCertificateFile:     spec.CaCertificateFile,
+			ProxyUrl:              spec.ProxyUrl,
+			ProxyUsername:         spec.ProxyUsername,
+			ProxyPassword:         spec.ProxyPassword,
+			NoProxy:               spec.NoProxy,
+		},
+	}, nil
+}
+
+var instanceVariable = regexp.MustCompile(`^STEADYBIT_EXTENSION_INSTANCE_(\d+)_`)
+
+// checkInstanceNumbering fails if an instance variable is set for an instance after the given number of instances,
+// which would be ignored because of a gap in the numbering.
+func checkInstanceNumbering(environ []string, count int) error {
+	var ignored []string
+	for _, variable := range environ {
+		name, _, _ := strings.Cut(variable, "=")
+		match := instanceVariable.FindStringSubmatch(strings.ToUpper(name))
+		if match == nil {
+			continue
+		}
+		if number, err := strconv.Atoi(match[1]); err != nil || number >= count {
+			ignored = append(ignored, name)
+		}
+	}
+	if len(ignored) == 0 {
+		return nil
+	}
+	slices.Sort(ignored)
+	return fmt.Errorf("instance variables %s would be ignored, because STEADYBIT_EXTENSION_INSTANCE_%d_API_BASE_URL is not set and instances must be numbered from 0 without gaps", strings.Join(ignored, ", "), count)
+}
+
+// ignoredConnectionSettings returns the connection settings without instance number which are set to non-default
+// values, although they are ignored in favour of the numbered instances.
+func ignoredConnectionSettings(spec Specification) []string {
+	settings := []struct {
+		name string
+		set  bool
+	}{
+		{"STEADYBIT_EXTENSION_INSTANCE_NAME", spec.InstanceName != "default"},
+		{"STEADYBIT_EXTENSION_AUTH_MODE", spec.AuthMode != AuthModeToken},
+		{"STEADYBIT_EXTENSION_ACCESS_TOKEN", spec.AccessToken != ""},
+		{"STEADYBIT_EXTENSION_ACCESS_TOKEN_FILE", spec.AccessTokenFile != ""},
+		{"STEADYBIT_EXTENSION_USERNAME", spec.Username != ""},
+		{"STEADYBIT_EXTENSION_PASSWORD", spec.Password != ""},
+		{"STEADYBIT_EXTENSION_API_BASE_URL", spec.ApiBaseUrl != ""},
+		{"STEADYBIT_EXTENSION_WEB_BASE_URL", spec.WebBaseUrl != ""},
+		{"STEADYBIT_EXTENSION_INSECURE_SKIP_VERIFY", spec.InsecureSkipVerify},
+		{"STEADYBIT_EXTENSION_CLIENT_CERTIFICATE_FILE", spec.ClientCertificateFile != ""},
+		{"STEADYBIT_EXTENSION_CLIENT_KEY_FILE", spec.ClientKeyFile != ""},
+		{"STEADYBIT_EXTENSION_CA_CERTIFICATE_FILE", spec.CaCertificateFile != ""},
+		{"STEADYBIT_EXTENSION_PROXY_URL", spec.ProxyUrl != ""},
+		{"STEADYBIT_EXTENSION_PROXY_USERNAME", spec.ProxyUsername != ""},
+		{"STEADYBIT_EXTENSION_PROXY_PASSWORD", spec.ProxyPassword != ""},
+		{"STEADYBIT_EXTENSION_NO_PROXY", len(spec.NoProxy) > 0},
+	}
+	var ignored []string
+	for _, setting := range settings {
+		if setting.set {
+			ignored = append(ignored, setting.name)
+		}
+	}
+	return ignored
+}
+
+// FindInstance returns the instance with the given name. An empty name returns the only instance, it is ambiguous if
+// there are several.
+func FindInstance(name string) (Instance, error) {
+	if name == "" {
+		if len(Config.Instances) != 1 {
+			return Instance{}, fmt.Errorf("no splunk instance given, but %d instances are configured", len(Config.Instances))
+		}
+		return Config.Instances[0], nil
+	}
+	for _, instance := range Config.Instances {
+		if instance.Name == name {
+			return instance, nil
+		}
+	}
+	return Instance{}, fmt.Errorf("splunk instance %q is not configured", name)
+}
+
+func validate(spec Specification) error {
+	if spec.MaxRetries < 0 {
+		return errors.New("max retries must not be negative")
+	}
+	if spec.RateLimit < 0 || spec.RateLimitBurst < 0 || spec.MaxConcurrentRequests < 0 {
+		return errors.New("rate limit, rate limit burst and max concurrent requests must not be negative")
+	}
+	if spec.HealthCheckFailureThreshold < 1 {
+		return errors.New("health check failure threshold must be at least 1")
+	}
+	names := make(map[string]bool, len(spec.Instances))
+	for _, instance := range spec.Instances {
+		if names[instance.Name] {
+			return fmt.Errorf("instance name %q is not unique", instance.Name)
+		}
+		names[instance.Name] = true
+		if err := validateInstance(instance); err != nil {
+			return fmt.Errorf("instance %q: %w", instance.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateInstance(instance Instance) error {
+	if instance.Name == "" {
+		return errors.New("name is required")
+	}
+	if instance.ApiBaseUrl == "" {
+		return errors.New("api base url is required")
+	}
+	switch instance.AuthMode {
+	case AuthModeToken:
+		if (instance.AccessToken == "") == (instance.AccessTokenFile == "") {
+			return errors.New("either access token or access token file is required for auth mode token")
+		}
+	case AuthModeBasic:
+		if instance.Username == "" || instance.Password == "" {
+			return errors.New("username and password are required for auth mode basic")
+		}
+	default:
+		return fmt.Errorf("unsupported auth mode %q, must be %q or %q", instance.AuthMode, AuthModeToken, AuthModeBasic)
+	}
+	if (instance.ClientCertificateFile == "") != (instance.ClientKeyFile == "") {
+		return errors.New("client certificate file and client key file must be set together")
+	}
+	if instance.ProxyUrl == "" && (instance.ProxyUsername != "" || len(instance.NoProxy) > 0) {
+		return errors.New("proxy username and no proxy require a proxy url")
+	}
+	return nil
 }
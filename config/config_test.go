@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package config
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestFindInstance(t *testing.T) {
+	Config.Instances = []Instance{{Name: "production"}, {Name: "staging"}}
+	t.Cleanup(func() { Config.Instances = nil })
+
+	instance, err := FindInstance("staging")
+	require.NoError(t, err)
+	require.Equal(t, "staging", instance.Name)
+
+	_, err = FindInstance("")
+	require.EqualError(t, err, "no splunk instance given, but 2 instances are configured")
+
+	_, err = FindInstance("unknown")
+	require.EqualError(t, err, `splunk instance "unknown" is not configured`)
+}
+
+func TestFindInstance_singleInstance(t *testing.T) {
+	Config.Instances = []Instance{{Name: "production"}}
+	t.Cleanup(func() { Config.Instances = nil })
+
+	instance, err := FindInstance("")
+	require.NoError(t, err)
+	require.Equal(t, "production", instance.Name)
+}
+
+func TestParseInstances(t *testing.T) {
+	t.Setenv("STEADYBIT_EXTENSION_INSTANCE_0_NAME", "production")
+	t.Setenv("STEADYBIT_EXTENSION_INSTANCE_0_API_BASE_URL", "https://production:8089")
+	t.Setenv("STEADYBIT_EXTENSION_INSTANCE_1_NAME", "staging")
+	t.Setenv("STEADYBIT_EXTENSION_INSTANCE_1_API_BASE_URL", "https://staging:8089")
+
+	instances, err := parseInstances(Specification{InstanceName: "default", AuthMode: AuthModeToken})
+
+	require.NoError(t, err)
+	require.Len(t, instances, 2)
+	require.Equal(t, "staging", instances[1].Name)
+}
+
+func TestParseInstances_gap(t *testing.T) {
+	t.Setenv("STEADYBIT_EXTENSION_INSTANCE_0_NAME", "production")
+	t.Setenv("STEADYBIT_EXTENSION_INSTANCE_0_API_BASE_URL", "https://production:8089")
+	t.Setenv("STEADYBIT_EXTENSION_INSTANCE_2_NAME", "staging")
+	t.Setenv("STEADYBIT_EXTENSION_INSTANCE_2_API_BASE_URL", "https://staging:8089")
+
+	_, err := parseInstances(Specification{InstanceName: "default", AuthMode: AuthModeToken})
+
+	require.EqualError(t, err, "instance variables STEADYBIT_EXTENSION_INSTANCE_2_API_BASE_URL, STEADYBIT_EXTENSION_INSTANCE_2_NAME would be ignored, because STEADYBIT_EXTENSION_INSTANCE_1_API_BASE_URL is not set and instances must be numbered from 0 without gaps")
+}
+
+func TestParseInstances_instanceWithoutApiBaseUrl(t *testing.T) {
+	t.Setenv("STEADYBIT_EXTENSION_INSTANCE_0_ACCESS_TOKEN", "token")
+
+	_, err := parseInstances(Specification{InstanceName: "default", AuthMode: AuthModeToken, ApiBaseUrl: "https://splunk:8089"})
+
+	require.EqualError(t, err, "instance variables STEADYBIT_EXTENSION_INSTANCE_0_ACCESS_TOKEN would be ignored, because STEADYBIT_EXTENSION_INSTANCE_0_API_BASE_URL is not set and instances must be numbered from 0 without gaps")
+}
+
+func TestIgnoredConnectionSettings(t *testing.T) {
+	require.Empty(t, ignoredConnectionSettings(Specification{InstanceName: "default", AuthMode: AuthModeToken}))
+	require.Equal(t, []string{"STEADYBIT_EXTENSION_ACCESS_TOKEN", "STEADYBIT_EXTENSION_API_BASE_URL"},
+		ignoredConnectionSettings(Specification{InstanceName: "default", AuthMode: AuthModeToken, AccessToken: "token", ApiBaseUrl: "https://splunk:8089"}))
+}
@@ -5,6 +5,7 @@
 package e2e
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -13,6 +14,8 @@ import (
 	"fmt"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/action-kit/go/action_kit_test/e2e"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"math/big"
 	"net"
 	"os"
@@ -123,3 +126,105 @@ func installConfigMap(m *e2e.Minikube) error {
 	}
 	return nil
 }
+
+// generateClientCert creates a self-signed client certificate for mutual TLS and returns a cleanup function
+func generateClientCert() (func(), error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate private key: %w", err)
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Organization: []string{"Steadybit Test"},
+			CommonName:   "extension-splunk-platform",
+		},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	certFile, err := writePem("client-cert*.pem", &pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	if err != nil {
+		return nil, err
+	}
+	keyFile, err := writePem("client-key*.pem", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Setenv("CLIENT_CERT_FILE", certFile); err != nil {
+		return nil, err
+	}
+	if err := os.Setenv("CLIENT_KEY_FILE", keyFile); err != nil {
+		return nil, err
+	}
+
+	cleanup := func() {
+		for _, file := range []string{certFile, keyFile} {
+			if err := os.Remove(file); err != nil {
+				log.Error().Err(err).Msgf("Failed to remove temporary file: %s", file)
+			}
+		}
+		for _, env := range []string{"CLIENT_CERT_FILE", "CLIENT_KEY_FILE"} {
+			if err := os.Unsetenv(env); err != nil {
+				log.Error().Err(err).Msgf("Failed to unset %s environment variable", env)
+			}
+		}
+	}
+
+	return cleanup, nil
+}
+
+func writePem(pattern string, block *pem.Block) (string, error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if err := pem.Encode(file, block); err != nil {
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// installClientCertSecret creates a Secret with the client certificate, its key and the CA certificate of the mock
+// server in the minikube cluster
+func installClientCertSecret(m *e2e.Minikube) error {
+	data := map[string][]byte{}
+	for key, env := range map[string]string{"tls.crt": "CLIENT_CERT_FILE", "tls.key": "CLIENT_KEY_FILE", "ca.crt": "CERT_FILE"} {
+		content, err := os.ReadFile(os.Getenv(env))
+		if err != nil {
+			return err
+		}
+		data[key] = content
+	}
+
+	_, err := m.GetClient().CoreV1().Secrets("default").Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "splunk-client-cert",
+			Namespace: "default",
+		},
+		Data: data,
+	}, metav1.CreateOptions{})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create Secret with client certificate")
+		return err
+	}
+	return nil
+}
@@ -27,11 +27,20 @@ func TestWithMinikube(t *testing.T) {
 	require.NoError(t, err)
 	defer cleanup()
 
+	cleanupClientCert, err := generateClientCert()
+	require.NoError(t, err)
+	defer cleanupClientCert()
+
 	server := createMockSplunkApiServer()
 	defer server.http.Close()
 	split := strings.SplitAfter(server.http.URL, ":")
 	port := split[len(split)-1]
 
+	mtlsServer := createMockSplunkApiServerWithClientAuth()
+	defer mtlsServer.http.Close()
+	split = strings.SplitAfter(mtlsServer.http.URL, ":")
+	mtlsPort := split[len(split)-1]
+
 	// Test with insecureSkipVerify approach
 	t.Run("with insecureSkipVerify", func(t *testing.T) {
 		extFactory := e2e.HelmExtensionFactory{
@@ -63,6 +72,10 @@ func TestWithMinikube(t *testing.T) {
 				Name: "test check action with insecureSkipVerify",
 				Test: testCheckAction,
 			},
+			{
+				Name: "test search check action with insecureSkipVerify",
+				Test: testSearchCheckAction,
+			},
 		})
 	})
 
@@ -105,6 +118,47 @@ func TestWithMinikube(t *testing.T) {
 				Name: "test check action with custom certificate",
 				Test: testCheckAction,
 			},
+			{
+				Name: "test search check action with custom certificate",
+				Test: testSearchCheckAction,
+			},
+		})
+	})
+
+	// Test with a management port requiring a client certificate
+	t.Run("with client certificate", func(t *testing.T) {
+		extFactory := e2e.HelmExtensionFactory{
+			Name: "extension-splunk-platform",
+			Port: 8083,
+			ExtraArgs: func(m *e2e.Minikube) []string {
+				return []string{
+					"--set", fmt.Sprintf("splunk.apiBaseUrl=https://host.minikube.internal:%s", mtlsPort),
+					"--set", "logging.level=trace",
+					"--set", "splunk.insecureSkipVerify=false",
+					"--set", "extraVolumeMounts[0].name=client-cert",
+					"--set", "extraVolumeMounts[0].mountPath=/etc/splunk-client-cert",
+					"--set", "extraVolumeMounts[0].readOnly=true",
+					"--set", "extraVolumes[0].name=client-cert",
+					"--set", "extraVolumes[0].secret.secretName=splunk-client-cert",
+					"--set", "extraEnv[0].name=STEADYBIT_EXTENSION_CLIENT_CERTIFICATE_FILE",
+					"--set", "extraEnv[0].value=/etc/splunk-client-cert/tls.crt",
+					"--set", "extraEnv[1].name=STEADYBIT_EXTENSION_CLIENT_KEY_FILE",
+					"--set", "extraEnv[1].value=/etc/splunk-client-cert/tls.key",
+					"--set", "extraEnv[2].name=STEADYBIT_EXTENSION_CA_CERTIFICATE_FILE",
+					"--set", "extraEnv[2].value=/etc/splunk-client-cert/ca.crt",
+				}
+			},
+		}
+
+		e2e.WithMinikube(t, e2e.DefaultMinikubeOpts().AfterStart(installClientCertSecret), &extFactory, []e2e.WithMinikubeTestCase{
+			{
+				Name: "test discovery with client certificate",
+				Test: testDiscovery,
+			},
+			{
+				Name: "test check action with client certificate",
+				Test: testCheckAction,
+			},
 		})
 	})
 }
@@ -165,3 +219,28 @@ func testCheckAction(t *testing.T, minikube *e2e.Minikube, e *e2e.Extension) {
 	require.NotEmpty(t, t, action.Messages())
 	require.NotEmpty(t, t, action.Metrics())
 }
+
+func testSearchCheckAction(t *testing.T, minikube *e2e.Minikube, e *e2e.Extension) {
+	config := struct {
+		Duration       int    `json:"duration"`
+		Query          string `json:"query"`
+		Field          string `json:"field"`
+		Operator       string `json:"operator"`
+		Threshold      string `json:"threshold"`
+		StateCheckMode string `json:"stateCheckMode"`
+	}{
+		Duration:       1_000,
+		Query:          "index=main | stats count",
+		Field:          "count",
+		Operator:       ">",
+		Threshold:      "0",
+		StateCheckMode: "allTheTime",
+	}
+
+	action, err := e.RunAction("com.steadybit.extension_splunk_platform.search.check", nil, config, &action_kit_api.ExecutionContext{})
+	require.NoError(t, err)
+	defer func() { _ = action.Cancel() }()
+
+	require.NoError(t, action.Wait())
+	require.NotEmpty(t, action.Metrics())
+}
@@ -5,6 +5,7 @@ package e2e
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
 	"net/http"
@@ -22,6 +23,28 @@ type mockServer struct {
 }
 
 func createMockSplunkApiServer() *mockServer {
+	return startMockSplunkApiServer(func(*tls.Config) {})
+}
+
+// createMockSplunkApiServerWithClientAuth starts a mock server requiring a client certificate signed by the CA in
+// CLIENT_CERT_FILE (set by generateClientCert), like a management port configured for mutual TLS.
+func createMockSplunkApiServerWithClientAuth() *mockServer {
+	clientCA, err := os.ReadFile(os.Getenv("CLIENT_CERT_FILE"))
+	if err != nil {
+		panic(fmt.Sprintf("httptest: failed to read client certificate: %v", err))
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(clientCA) {
+		panic("httptest: failed to parse client certificate")
+	}
+
+	return startMockSplunkApiServer(func(tlsConfig *tls.Config) {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		tlsConfig.ClientCAs = clientCAs
+	})
+}
+
+func startMockSplunkApiServer(configureTLS func(*tls.Config)) *mockServer {
 	// Load certificate from environment variables (set by generateSelfSignedCert)
 	serverCert, err := tls.LoadX509KeyPair(os.Getenv("CERT_FILE"), os.Getenv("KEY_FILE"))
 	if err != nil {
@@ -44,13 +67,20 @@ func createMockSplunkApiServer() *mockServer {
 			Certificates: []tls.Certificate{serverCert},
 		},
 	}
+	configureTLS(server.TLS)
 
 	server.StartTLS()
 	log.Info().Str("url", server.URL).Msg("Started Secure Mock-Server with self-signed certificate")
 
 	mock := &mockServer{http: &server}
+	mux.Handle("GET /services/server/info", handler(mock.getServerInfo))
+	mux.Handle("GET /services/authentication/current-context", handler(mock.getCurrentContext))
 	mux.Handle("GET /services/saved/searches", handler(mock.getSavedSearches))
+	mux.Handle("GET /services/data/indexes", handler(mock.getIndexes))
 	mux.Handle("GET /servicesNS/nobody/myTestApp/user/alerts/Enty%201", handler(mock.getFiredAlerts))
+	mux.Handle("POST /services/search/jobs", handler(mock.dispatchSearch))
+	mux.Handle("GET /services/search/jobs/e2e-search", handler(mock.getSearchJob))
+	mux.Handle("GET /services/search/jobs/e2e-search/results", handler(mock.getSearchResults))
 	return mock
 }
 
@@ -58,6 +88,42 @@ func handler[T any](getter func() T) http.Handler {
 	return exthttp.PanicRecovery(exthttp.LogRequestWithDefaultLogLevel(exthttp.GetterAsHandler(getter), zerolog.DebugLevel))
 }
 
+func (m *mockServer) getServerInfo() extalert.ServerInfoResponse {
+	return extalert.ServerInfoResponse{
+		Entries: []extalert.ServerInfoEntry{
+			{Content: extalert.ServerInfoContent{ServerName: "e2e", Version: "9.4.2"}},
+		},
+	}
+}
+
+func (m *mockServer) getCurrentContext() extalert.CurrentContextResponse {
+	return extalert.CurrentContextResponse{
+		Entries: []extalert.CurrentContextEntry{
+			{Content: extalert.CurrentContextContent{Username: "e2e", Roles: []string{"user"}, Capabilities: []string{"search"}}},
+		},
+	}
+}
+
+func (m *mockServer) getIndexes() extalert.Response {
+	return extalert.Response{
+		Paging: extalert.Paging{
+			Total:   1,
+			PerPage: 30,
+			Offset:  0,
+		},
+		Entries: []extalert.Entry{
+			{
+				Id:   "index-main",
+				Name: "main",
+				Content: extalert.Content{
+					Datatype:        "event",
+					TotalEventCount: 42,
+				},
+			},
+		},
+	}
+}
+
 func (m *mockServer) getSavedSearches() extalert.Response {
 	return extalert.Response{
 		Paging: extalert.Paging{
@@ -101,3 +167,33 @@ func (m *mockServer) getFiredAlerts() extalert.Response {
 		},
 	}
 }
+
+func (m *mockServer) dispatchSearch() extalert.DispatchResponse {
+	return extalert.DispatchResponse{
+		Sid: "e2e-search",
+	}
+}
+
+func (m *mockServer) getSearchJob() extalert.SearchJobResponse {
+	return extalert.SearchJobResponse{
+		Entries: []extalert.SearchJobEntry{
+			{
+				Name: "e2e-search",
+				Content: extalert.SearchJobContent{
+					Sid:           "e2e-search",
+					DispatchState: "DONE",
+					IsDone:        true,
+					ResultCount:   1,
+				},
+			},
+		},
+	}
+}
+
+func (m *mockServer) getSearchResults() extalert.SearchResultsResponse {
+	return extalert.SearchResultsResponse{
+		Results: []map[string]any{
+			{"count": "42"},
+		},
+	}
+}
@@ -0,0 +1,432 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+	"strings"
+	"time"
+)
+
+type AggregateAlertClient interface {
+	PreflightClient
+	AlertClient
+	FilteredFiredAlertsClient
+}
+
+type AggregateAlertCheckAction struct {
+	// Instances are the configured instances the alerts are looked up on.
+	Instances []string
+	Clients   ClientResolver[AggregateAlertClient]
+}
+
+var (
+	_ action_kit_sdk.Action[AggregateAlertCheckState]           = (*AggregateAlertCheckAction)(nil)
+	_ action_kit_sdk.ActionWithStatus[AggregateAlertCheckState] = (*AggregateAlertCheckAction)(nil)
+)
+
+type AggregateAlertCheckState struct {
+	Alerts             []AggregateAlert
+	Aggregation        string
+	CheckNewAlertsOnly bool
+	Start              time.Time
+	End                time.Time
+	FailEarly          bool
+	// DeviationTitle remembers the first observed deviation in 'None of' + fail-at-end mode
+	// (FailEarly = false) so it can be reported once the step ends.
+	DeviationTitle string
+	FiredAlertFilter
+	// PreviousAlertsFetched is whether the newest alerts fired before the step were fetched, which are considered
+	// unless only new alerts are checked.
+	PreviousAlertsFetched bool
+}
+
+type AggregateAlert struct {
+	Id   string
+	Name string
+	Url  string
+	// Instance is the instance the alert was found on.
+	Instance string
+	// TriggerTime is the trigger time of the first fired alert observed, 0 if the alert was not observed firing.
+	TriggerTime int64
+	// LatestTriggerTime is the trigger time of the newest fired alert observed, 0 if the alert was not observed firing.
+	LatestTriggerTime int64
+	// FiredAlertsCursor is the latest trigger time fetched so far. Only alerts fired at or after it are fetched again.
+	FiredAlertsCursor int64
+}
+
+const (
+	aggregationAnyOf  = "anyOf"
+	aggregationAllOf  = "allOf"
+	aggregationNoneOf = "noneOf"
+)
+
+func NewAggregateAlertCheckAction(instances []string, clients ClientResolver[AggregateAlertClient]) action_kit_sdk.Action[AggregateAlertCheckState] {
+	return &AggregateAlertCheckAction{
+		Instances: instances,
+		Clients:   clients,
+	}
+}
+
+func (a *AggregateAlertCheckAction) NewEmptyState() AggregateAlertCheckState {
+	return AggregateAlertCheckState{}
+}
+
+func (a *AggregateAlertCheckAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.aggregate-check", TargetType),
+		Label:       "Alert Group Status",
+		Description: "Check the status of several alerts together.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        new(targetIcon),
+		Technology:  new("Splunk"),
+		Category:    new("Monitoring"),
+		Kind:        action_kit_api.Check,
+		TimeControl: action_kit_api.TimeControlInternal,
+		Parameters: append([]action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("30s"),
+				Required:     new(true),
+			},
+			{
+				Name:        "alerts",
+				Label:       "Alerts",
+				Description: new("The IDs of the alerts to check, as discovered on the alert targets. Each alert is checked on the Splunk instance it was discovered from."),
+				Type:        action_kit_api.ActionParameterTypeStringArray,
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ParameterOptionsFromTargetAttribute{
+						Attribute: attributeID,
+					},
+				}),
+				Required: new(true),
+			},
+			{
+				Name:         "aggregation",
+				Label:        "Expectation",
+				Description:  new("How many of the alerts should have fired?"),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new(aggregationAnyOf),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Any of the alerts fired",
+						Value: aggregationAnyOf,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "All of the alerts fired",
+						Value: aggregationAllOf,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "None of the alerts fired",
+						Value: aggregationNoneOf,
+					},
+				}),
+				Required: new(true),
+			},
+			{
+				Name:         "checkNewAlertsOnly",
+				Label:        "New Alerts Only",
+				Description:  new("Only check events fired after the start of the experiment."),
+				Type:         action_kit_api.ActionParameterTypeBoolean,
+				DefaultValue: new("false"),
+				Required:     new(true),
+			},
+			{
+				Name:         "failEarly",
+				Label:        "Fail early",
+				Description:  new("If enabled, the check fails as soon as a deviating state is observed. If disabled, the check keeps collecting events for the whole duration and only fails at the end of the step. Only affects the 'None of the alerts fired' expectation; the others can only be evaluated at the end of the step."),
+				Type:         action_kit_api.ActionParameterTypeBoolean,
+				DefaultValue: new("true"),
+				Advanced:     new(true),
+				Required:     new(false),
+			},
+		}, firedAlertFilterParameters()...),
+		Widgets: new([]action_kit_api.Widget{
+			action_kit_api.StateOverTimeWidget{
+				Type:  action_kit_api.ComSteadybitWidgetStateOverTime,
+				Title: "Alert State",
+				Identity: action_kit_api.StateOverTimeWidgetIdentityConfig{
+					From: metricId,
+				},
+				Label: action_kit_api.StateOverTimeWidgetLabelConfig{
+					From: metricLabel,
+				},
+				State: action_kit_api.StateOverTimeWidgetStateConfig{
+					From: metricState,
+				},
+				Tooltip: action_kit_api.StateOverTimeWidgetTooltipConfig{
+					From: metricTooltip,
+				},
+				Value: new(action_kit_api.StateOverTimeWidgetValueConfig{
+					Hide: new(true),
+				}),
+			},
+		}),
+		Status: new(action_kit_api.MutatingEndpointReferenceWithCallInterval{
+			CallInterval: new("1s"),
+		}),
+	}
+}
+
+func (a *AggregateAlertCheckAction) Prepare(ctx context.Context, state *AggregateAlertCheckState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	alertIds := extutil.ToStringArray(request.Config["alerts"])
+	if len(alertIds) == 0 {
+		return nil, fmt.Errorf("alerts parameter is missing")
+	}
+
+	aggregation := extutil.ToString(request.Config["aggregation"])
+	switch aggregation {
+	case aggregationAnyOf, aggregationAllOf, aggregationNoneOf:
+	default:
+		return nil, fmt.Errorf("unsupported expectation %q", aggregation)
+	}
+
+	alerts, err := a.findAlerts(ctx, alertIds)
+	if err != nil {
+		return nil, err
+	}
+	filter := newFiredAlertFilter(request.Config)
+	capabilities := []string{capabilityListSavedSearches}
+	if filter.ResultField != "" {
+		capabilities = append(capabilities, capabilitySearch)
+	}
+	preflighted := make(map[string]bool)
+	for _, alert := range alerts {
+		if preflighted[alert.Instance] {
+			continue
+		}
+		if err := preflight(ctx, a.Clients, alert.Instance, capabilities...); err != nil {
+			return nil, err
+		}
+		preflighted[alert.Instance] = true
+	}
+
+	start := time.Now()
+	state.Alerts = alerts
+	state.FiredAlertFilter = filter
+	state.Aggregation = aggregation
+	state.CheckNewAlertsOnly = extutil.ToBool(request.Config["checkNewAlertsOnly"])
+	// the history of the alerts is not fetched, only the newest alert fired before the step unless only new alerts
+	// are checked
+	for i := range state.Alerts {
+		state.Alerts[i].FiredAlertsCursor = start.Unix()
+	}
+	state.Start = start
+	state.End = start.Add(time.Duration(extutil.ToInt64(request.Config["duration"])) * time.Millisecond)
+	state.FailEarly = true
+	if request.Config["failEarly"] != nil {
+		state.FailEarly = extutil.ToBool(request.Config["failEarly"])
+	}
+
+	log.Trace().Any("state", state).Msg("aggregate check action state")
+
+	return nil, nil
+}
+
+// findAlerts looks the alerts with the given IDs up on the configured instances. Instances whose alerts can't be listed
+// are skipped unless an alert is not found on any other instance.
+func (a *AggregateAlertCheckAction) findAlerts(ctx context.Context, alertIds []string) ([]AggregateAlert, error) {
+	found := make(map[string][]AggregateAlert, len(alertIds))
+	for _, id := range alertIds {
+		found[id] = nil
+	}
+	var listErrs []error
+	for _, instance := range a.Instances {
+		client, err := a.Clients(instance)
+		if err != nil {
+			return nil, err
+		}
+		alerts, err := client.Alerts(ctx)
+		if err != nil {
+			listErrs = append(listErrs, fmt.Errorf("instance %q: %w", instance, toExtensionError(err)))
+			continue
+		}
+		for _, alert := range alerts {
+			if matching, ok := found[alert.Id]; ok {
+				found[alert.Id] = append(matching, AggregateAlert{Id: alert.Id, Name: alert.Name, Url: alert.Links.Alerts, Instance: instance})
+			}
+		}
+	}
+
+	var result []AggregateAlert
+	var unknown, ambiguous []string
+	for _, id := range alertIds {
+		switch matching := found[id]; len(matching) {
+		case 0:
+			unknown = append(unknown, id)
+		case 1:
+			result = append(result, matching[0])
+		default:
+			ambiguous = append(ambiguous, fmt.Sprintf("%s (%s)", id, alertInstances(matching)))
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, errors.Join(append([]error{fmt.Errorf("alerts not found: %s", strings.Join(unknown, ", "))}, listErrs...)...)
+	}
+	if len(ambiguous) > 0 {
+		return nil, fmt.Errorf("alerts found on several instances: %s", strings.Join(ambiguous, ", "))
+	}
+	return result, nil
+}
+
+func (a *AggregateAlertCheckAction) Start(ctx context.Context, state *AggregateAlertCheckState) (*action_kit_api.StartResult, error) {
+	statusResult, err := checkAggregateFiredAlerts(ctx, state, a.Clients)
+	if statusResult == nil {
+		return nil, toExtensionError(err)
+	}
+	return &action_kit_api.StartResult{
+		Artifacts: statusResult.Artifacts,
+		Error:     statusResult.Error,
+		Messages:  statusResult.Messages,
+		Metrics:   statusResult.Metrics,
+	}, toExtensionError(err)
+}
+
+func (a *AggregateAlertCheckAction) Status(ctx context.Context, state *AggregateAlertCheckState) (*action_kit_api.StatusResult, error) {
+	statusResult, err := checkAggregateFiredAlerts(ctx, state, a.Clients)
+	return statusResult, toExtensionError(err)
+}
+
+func checkAggregateFiredAlerts(ctx context.Context, state *AggregateAlertCheckState, clients ClientResolver[AggregateAlertClient]) (*action_kit_api.StatusResult, error) {
+	now := time.Now()
+
+	var metrics []action_kit_api.Metric
+	for i := range state.Alerts {
+		alert := &state.Alerts[i]
+		client, err := clients(alert.Instance)
+		if err != nil {
+			return nil, err
+		}
+		if !state.PreviousAlertsFetched && !state.CheckNewAlertsOnly {
+			previousTriggerTime, err := fetchPreviousTriggerTime(ctx, client, &state.FiredAlertFilter, alert.Url, state.Start)
+			if err != nil {
+				return nil, err
+			}
+			if previousTriggerTime != 0 {
+				alert.TriggerTime = previousTriggerTime
+				alert.LatestTriggerTime = previousTriggerTime
+			}
+		}
+		fetchedAlerts, err := client.FiredAlertsSince(ctx, alert.Url, alert.FiredAlertsCursor)
+		if err != nil {
+			return nil, err
+		}
+
+		var newFiredAlerts []FiredAlert
+		for _, entry := range fetchedAlerts {
+			alert.FiredAlertsCursor = max(alert.FiredAlertsCursor, entry.Content.TriggerTime)
+			if !state.CheckNewAlertsOnly || firedAfterStart(state.Start, entry.Content.TriggerTime) {
+				newFiredAlerts = append(newFiredAlerts, newFiredAlert(entry))
+			}
+		}
+		firedAlerts, err := state.FiredAlertFilter.filter(ctx, client, newFiredAlerts)
+		if err != nil {
+			return nil, err
+		}
+		if len(firedAlerts) > 0 {
+			sortFiredAlerts(firedAlerts)
+			if alert.TriggerTime == 0 {
+				alert.TriggerTime = firedAlerts[0].TriggerTime
+			}
+			alert.LatestTriggerTime = max(alert.LatestTriggerTime, latestTriggerTime(firedAlerts))
+		}
+		metrics = append(metrics, toMetrics(alert.Id, alert.Name, alert.LatestTriggerTime, now)...)
+	}
+	state.PreviousAlertsFetched = true
+
+	completed := now.After(state.End)
+	var checkError *action_kit_api.ActionKitError
+	switch state.Aggregation {
+	case aggregationAnyOf:
+		if completed && len(firedAggregateAlerts(state)) == 0 {
+			checkError = new(action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("At least one of the alerts %s should have been fired but none was.", quoteAlertNames(state.Alerts)),
+				Status: extutil.Ptr(action_kit_api.Failed),
+			})
+		}
+	case aggregationAllOf:
+		if notFired := notFiredAggregateAlerts(state); completed && len(notFired) > 0 {
+			checkError = new(action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("All alerts should have been fired but %s did not fire.", quoteAlertNames(notFired)),
+				Status: extutil.Ptr(action_kit_api.Failed),
+			})
+		}
+	case aggregationNoneOf:
+		checkError = checkNoneOfFired(state, completed)
+	}
+
+	return &action_kit_api.StatusResult{
+		Completed: completed,
+		Error:     checkError,
+		Metrics:   new(metrics),
+	}, nil
+}
+
+func checkNoneOfFired(state *AggregateAlertCheckState, completed bool) *action_kit_api.ActionKitError {
+	if fired := firedAggregateAlerts(state); len(fired) > 0 {
+		triggerTime := time.Unix(fired[0].TriggerTime, 0).UTC().Format(time.RFC3339)
+		title := fmt.Sprintf("None of the alerts should have been fired but %q was at %s.", fired[0].Name, triggerTime)
+		if state.FailEarly {
+			return new(action_kit_api.ActionKitError{
+				Title:  title,
+				Status: extutil.Ptr(action_kit_api.Failed),
+			})
+		} else if state.DeviationTitle == "" {
+			state.DeviationTitle = title
+		}
+	}
+	if !state.FailEarly && completed && state.DeviationTitle != "" {
+		return new(action_kit_api.ActionKitError{
+			Title:  state.DeviationTitle,
+			Status: extutil.Ptr(action_kit_api.Failed),
+		})
+	}
+	return nil
+}
+
+func firedAggregateAlerts(state *AggregateAlertCheckState) []AggregateAlert {
+	var fired []AggregateAlert
+	for _, alert := range state.Alerts {
+		if alert.TriggerTime != 0 {
+			fired = append(fired, alert)
+		}
+	}
+	return fired
+}
+
+func notFiredAggregateAlerts(state *AggregateAlertCheckState) []AggregateAlert {
+	var notFired []AggregateAlert
+	for _, alert := range state.Alerts {
+		if alert.TriggerTime == 0 {
+			notFired = append(notFired, alert)
+		}
+	}
+	return notFired
+}
+
+// alertInstances lists the instances of the alerts, to tell alerts found on several instances apart.
+func alertInstances(alerts []AggregateAlert) string {
+	instances := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		instances = append(instances, alert.Instance)
+	}
+	return strings.Join(instances, ", ")
+}
+
+func quoteAlertNames(alerts []AggregateAlert) string {
+	names := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		names = append(names, fmt.Sprintf("%q", alert.Name))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"errors"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+var aggregateMockAlerts = []Entry{
+	{Id: "/saved/one", Name: "Alert One", Links: Links{Alerts: "/alerts/one"}, ACL: ACL{App: "search", Owner: "nobody"}},
+	{Id: "/saved/two", Name: "Alert Two", Links: Links{Alerts: "/alerts/two"}},
+	{Id: "/saved/three", Name: "Alert Three", Links: Links{Alerts: "/alerts/three"}},
+}
+
+func TestAggregateAlertCheckAction_Describe_NoError(t *testing.T) {
+	action := NewAggregateAlertCheckAction(nil, nil)
+
+	description := action.Describe()
+
+	require.NotNil(t, description)
+}
+
+func TestAggregateAlertCheckAction_Prepare(t *testing.T) {
+	action := NewAggregateAlertCheckAction([]string{"default"}, resolveTo[AggregateAlertClient](MockSplunkClient{response: aggregateMockAlerts}))
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{
+			"duration":    1000,
+			"alerts":      []any{"/saved/one", "/saved/three"},
+			"aggregation": aggregationAllOf,
+		},
+	})
+
+	require.NoError(t, err)
+	cursor := state.Start.Unix()
+	require.Equal(t, []AggregateAlert{
+		{Id: "/saved/one", Name: "Alert One", Url: "/alerts/one", Instance: "default", FiredAlertsCursor: cursor},
+		{Id: "/saved/three", Name: "Alert Three", Url: "/alerts/three", Instance: "default", FiredAlertsCursor: cursor},
+	}, state.Alerts)
+	require.Equal(t, aggregationAllOf, state.Aggregation)
+	require.Greater(t, state.End, state.Start)
+	require.True(t, state.FailEarly)
+}
+
+func TestAggregateAlertCheckAction_Prepare_alertsOfSeveralInstances(t *testing.T) {
+	clients := map[string]MockSplunkClient{
+		"prod": {response: aggregateMockAlerts[:1]},
+		"test": {response: []Entry{{Id: "/saved/test", Name: "Alert One", Links: Links{Alerts: "/alerts/test"}}}},
+	}
+	action := NewAggregateAlertCheckAction([]string{"prod", "test"}, func(instance string) (AggregateAlertClient, error) {
+		return clients[instance], nil
+	})
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{
+			"duration":    1000,
+			"alerts":      []any{"/saved/one", "/saved/test"},
+			"aggregation": aggregationAllOf,
+		},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, state.Alerts, 2)
+	require.Equal(t, "prod", state.Alerts[0].Instance)
+	require.Equal(t, "test", state.Alerts[1].Instance)
+	require.Equal(t, "Alert One", state.Alerts[1].Name, "alerts sharing a name are told apart by their id")
+}
+
+func TestAggregateAlertCheckAction_Prepare_unknownAlert(t *testing.T) {
+	action := NewAggregateAlertCheckAction([]string{"default"}, resolveTo[AggregateAlertClient](MockSplunkClient{response: aggregateMockAlerts}))
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{
+			"duration":    1000,
+			"alerts":      []any{"/saved/one", "/saved/four"},
+			"aggregation": aggregationAnyOf,
+		},
+	})
+
+	require.EqualError(t, err, "alerts not found: /saved/four")
+}
+
+func TestAggregateAlertCheckAction_Prepare_skipsUnreachableInstance(t *testing.T) {
+	clients := map[string]MockSplunkClient{
+		"prod": {err: errors.New("connection refused")},
+		"test": {response: aggregateMockAlerts},
+	}
+	action := NewAggregateAlertCheckAction([]string{"prod", "test"}, func(instance string) (AggregateAlertClient, error) {
+		return clients[instance], nil
+	})
+	state := action.NewEmptyState()
+	config := map[string]any{
+		"duration":    1000,
+		"alerts":      []any{"/saved/one"},
+		"aggregation": aggregationAnyOf,
+	}
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{Config: config})
+	require.NoError(t, err)
+	require.Equal(t, "test", state.Alerts[0].Instance)
+
+	config["alerts"] = []any{"/saved/four"}
+	_, err = action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{Config: config})
+	require.ErrorContains(t, err, "alerts not found: /saved/four")
+	require.ErrorContains(t, err, `instance "prod": connection refused`)
+}
+
+func TestAggregateAlertCheckAction_Prepare_alertOnSeveralInstances(t *testing.T) {
+	action := NewAggregateAlertCheckAction([]string{"prod", "test"}, resolveTo[AggregateAlertClient](MockSplunkClient{response: aggregateMockAlerts}))
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{
+			"duration":    1000,
+			"alerts":      []any{"/saved/one"},
+			"aggregation": aggregationAnyOf,
+		},
+	})
+
+	require.EqualError(t, err, "alerts found on several instances: /saved/one (prod, test)")
+}
+
+func aggregateState(aggregation string, end time.Time) AggregateAlertCheckState {
+	return AggregateAlertCheckState{
+		Alerts: []AggregateAlert{
+			{Id: "/saved/one", Name: "Alert One", Url: "/alerts/one"},
+			{Id: "/saved/two", Name: "Alert Two", Url: "/alerts/two"},
+		},
+		Aggregation: aggregation,
+		Start:       time.Now().Add(-2 * time.Minute),
+		End:         end,
+		FailEarly:   true,
+	}
+}
+
+func TestAggregateAlertCheckAction_anyOf(t *testing.T) {
+	state := aggregateState(aggregationAnyOf, time.Now().Add(-1*time.Second))
+
+	result, err := checkAggregateFiredAlerts(t.Context(), &state, resolveTo[AggregateAlertClient](MockSplunkClient{firedAlerts: map[string][]Entry{}}))
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `At least one of the alerts "Alert One", "Alert Two" should have been fired but none was.`, result.Error.Title)
+
+	result, err = checkAggregateFiredAlerts(t.Context(), &state, resolveTo[AggregateAlertClient](MockSplunkClient{firedAlerts: map[string][]Entry{"/alerts/two": firedAlertsAt(946684800)}}))
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+	require.Len(t, *result.Metrics, 2, "one widget lane per alert")
+}
+
+func TestAggregateAlertCheckAction_allOf(t *testing.T) {
+	state := aggregateState(aggregationAllOf, time.Now().Add(1*time.Minute))
+	client := MockSplunkClient{firedAlerts: map[string][]Entry{"/alerts/one": firedAlertsAt(946684800)}}
+
+	result, err := checkAggregateFiredAlerts(t.Context(), &state, resolveTo[AggregateAlertClient](client))
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+
+	state.End = time.Now().Add(-1 * time.Second)
+	result, err = checkAggregateFiredAlerts(t.Context(), &state, resolveTo[AggregateAlertClient](client))
+	require.NoError(t, err)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `All alerts should have been fired but "Alert Two" did not fire.`, result.Error.Title)
+
+	client.firedAlerts["/alerts/two"] = firedAlertsAt(946684900)
+	result, err = checkAggregateFiredAlerts(t.Context(), &state, resolveTo[AggregateAlertClient](client))
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+}
+
+func TestAggregateAlertCheckAction_noneOf_failEarly(t *testing.T) {
+	state := aggregateState(aggregationNoneOf, time.Now().Add(1*time.Minute))
+
+	result, err := checkAggregateFiredAlerts(t.Context(), &state, resolveTo[AggregateAlertClient](MockSplunkClient{firedAlerts: map[string][]Entry{"/alerts/two": firedAlertsAt(946684800)}}))
+
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `None of the alerts should have been fired but "Alert Two" was at 2000-01-01T00:00:00Z.`, result.Error.Title)
+}
+
+func TestAggregateAlertCheckAction_noneOf_minSeverity(t *testing.T) {
+	state := aggregateState(aggregationNoneOf, time.Now().Add(-1*time.Second))
+	state.MinSeverity = SeveritySevere
+	firedAlerts := map[string][]Entry{"/alerts/two": {{Content: Content{TriggerTime: 946684800, FiredSeverity: SeverityInfo}}}}
+
+	result, err := checkAggregateFiredAlerts(t.Context(), &state, resolveTo[AggregateAlertClient](MockSplunkClient{firedAlerts: firedAlerts}))
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+
+	firedAlerts["/alerts/one"] = []Entry{{Content: Content{TriggerTime: 946684900, FiredSeverity: SeverityFatal}}}
+	result, err = checkAggregateFiredAlerts(t.Context(), &state, resolveTo[AggregateAlertClient](MockSplunkClient{firedAlerts: firedAlerts}))
+	require.NoError(t, err)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `None of the alerts should have been fired but "Alert One" was at 2000-01-01T00:01:40Z.`, result.Error.Title)
+}
+
+func TestAggregateAlertCheckAction_noneOf_newAlertsOnly(t *testing.T) {
+	state := aggregateState(aggregationNoneOf, time.Now().Add(-1*time.Second))
+	state.CheckNewAlertsOnly = true
+
+	result, err := checkAggregateFiredAlerts(t.Context(), &state, resolveTo[AggregateAlertClient](MockSplunkClient{firedAlerts: map[string][]Entry{"/alerts/two": firedAlertsAt(946684800)}}))
+
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+	require.Nil(t, result.Error)
+}
+
+func TestAggregateAlertCheckAction_fetchesIncrementally(t *testing.T) {
+	state := aggregateState(aggregationAnyOf, time.Now().Add(1*time.Minute))
+	state.Start = time.Unix(946684700, 0)
+	client := MockSplunkClient{firedAlerts: map[string][]Entry{"/alerts/one": firedAlertsAt(946684900, 946684800)}}
+
+	_, err := checkAggregateFiredAlerts(t.Context(), &state, resolveTo[AggregateAlertClient](client))
+	require.NoError(t, err)
+	require.Equal(t, int64(946684900), state.Alerts[0].FiredAlertsCursor)
+	require.Equal(t, int64(946684800), state.Alerts[0].TriggerTime)
+	require.Equal(t, int64(946684900), state.Alerts[0].LatestTriggerTime)
+	require.Zero(t, state.Alerts[1].FiredAlertsCursor)
+
+	client.firedAlerts["/alerts/one"] = firedAlertsAt(946685000, 946684900, 946684800)
+	result, err := checkAggregateFiredAlerts(t.Context(), &state, resolveTo[AggregateAlertClient](client))
+	require.NoError(t, err)
+	require.Equal(t, int64(946685000), state.Alerts[0].FiredAlertsCursor)
+	require.Equal(t, int64(946684800), state.Alerts[0].TriggerTime)
+	require.Equal(t, int64(946685000), state.Alerts[0].LatestTriggerTime)
+	require.Equal(t, "2000-01-01T00:03:20Z", (*result.Metrics)[0].Metric[metricTriggerTime])
+}
+
+func TestAggregateAlertCheckAction_considersPreviousAlertOnly(t *testing.T) {
+	state := aggregateState(aggregationAllOf, time.Now().Add(-1*time.Second))
+	for i := range state.Alerts {
+		state.Alerts[i].FiredAlertsCursor = state.Start.Unix()
+	}
+	client := MockSplunkClient{firedAlerts: map[string][]Entry{"/alerts/one": firedAlertsAt(946684900, 946684800)}}
+
+	result, err := checkAggregateFiredAlerts(t.Context(), &state, resolveTo[AggregateAlertClient](client))
+
+	require.NoError(t, err)
+	require.Equal(t, int64(946684900), state.Alerts[0].TriggerTime, "only the newest alert fired before the step is fetched")
+	require.Equal(t, int64(946684900), state.Alerts[0].LatestTriggerTime)
+	require.Equal(t, `All alerts should have been fired but "Alert Two" did not fire.`, result.Error.Title)
+}
@@ -0,0 +1,253 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/extension-splunk-platform/config"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	loginPath = "/services/auth/login"
+	// tokenFileCheckInterval is how often the token file is checked for a rotated token.
+	tokenFileCheckInterval = 10 * time.Second
+	// tokenExpiryWarningPeriod is how long before the expiry of the token a warning is logged.
+	tokenExpiryWarningPeriod = 1 * time.Hour
+)
+
+type loginResponse struct {
+	SessionKey string `json:"sessionKey"`
+}
+
+// sessionAuth authenticates requests with a session key obtained by logging in with username and password. The
+// session key is cached until Splunk rejects it, e.g. because the session timed out, which triggers a new login.
+type sessionAuth struct {
+	// login is a separate client sharing the transport, so logging in doesn't run into the hooks of the main client.
+	login    *resty.Client
+	username string
+	password string
+
+	mu         sync.Mutex
+	sessionKey string
+}
+
+func useSessionAuth(client *resty.Client, username, password string) {
+	login := resty.NewWithClient(client.GetClient()).SetBaseURL(client.BaseURL)
+	// A busy Splunk rejects logins just like other requests, so they are retried alike.
+	useRetries(login, client.RetryCount, client.RetryWaitTime, client.RetryMaxWaitTime)
+	auth := &sessionAuth{
+		login:    login,
+		username: username,
+		password: password,
+	}
+	client.OnBeforeRequest(auth.authenticate)
+	// Retry once with a fresh session key if the cached one was rejected.
+	client.AddRetryCondition(auth.expireRejectedSession)
+	client.SetRetryCount(max(client.RetryCount, 1))
+}
+
+func useAuth(client *resty.Client, instance config.Instance) error {
+	switch instance.AuthMode {
+	case config.AuthModeBasic:
+		useSessionAuth(client, instance.Username, instance.Password)
+		return nil
+	default:
+		return useTokenAuth(client, instance.AccessToken, instance.AccessTokenFile)
+	}
+}
+
+func (a *sessionAuth) authenticate(_ *resty.Client, request *resty.Request) error {
+	sessionKey, err := a.session(request.Context())
+	if err != nil {
+		return err
+	}
+	request.SetHeader("Authorization", "Splunk "+sessionKey)
+	return nil
+}
+
+func (a *sessionAuth) session(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.sessionKey != "" {
+		return a.sessionKey, nil
+	}
+
+	var response loginResponse
+	res, err := a.login.R().
+		SetContext(ctx).
+		SetResult(&response).
+		SetFormData(map[string]string{
+			"username":    a.username,
+			"password":    a.password,
+			"output_mode": "json",
+		}).
+		Post(loginPath)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to log in to Splunk: %w", err)
+	}
+
+	if res.StatusCode() != 200 {
+		return "", fmt.Errorf("failed to log in to Splunk as %q: %w", a.username, newSplunkError(res.StatusCode(), res.Body()))
+	}
+
+	if response.SessionKey == "" {
+		return "", fmt.Errorf("login response is missing the session key. full response: %v", res.String())
+	}
+
+	log.Debug().Str("username", a.username).Msg("Logged in to Splunk")
+	a.sessionKey = response.SessionKey
+	return a.sessionKey, nil
+}
+
+// expireRejectedSession drops the cached session key if Splunk rejected it. The key is only dropped if it is still the
+// one used by the request, so concurrent rejections don't discard a session key obtained in the meantime.
+func (a *sessionAuth) expireRejectedSession(res *resty.Response, _ error) bool {
+	if res == nil || res.StatusCode() != http.StatusUnauthorized {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if res.Request.Header.Get("Authorization") == "Splunk "+a.sessionKey {
+		a.sessionKey = ""
+	}
+	return true
+}
+
+// tokenAuth authenticates requests with a bearer token. A token read from a file, e.g. a mounted Kubernetes secret, is
+// read again when it may have been rotated, so the new token is used without a restart.
+type tokenAuth struct {
+	file string
+
+	mu           sync.Mutex
+	token        string
+	readAt       time.Time
+	expiryWarned bool
+}
+
+func useTokenAuth(client *resty.Client, token, file string) error {
+	auth := &tokenAuth{file: file}
+	if file == "" {
+		auth.setToken(token)
+	} else if _, err := auth.read(); err != nil {
+		return err
+	}
+	client.OnBeforeRequest(auth.authenticate)
+	if file != "" {
+		// Retry once with the token from the file if it was rotated in the meantime.
+		client.AddRetryCondition(auth.rereadRejectedToken)
+		client.SetRetryCount(max(client.RetryCount, 1))
+	}
+	return nil
+}
+
+func (a *tokenAuth) authenticate(_ *resty.Client, request *resty.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file != "" && time.Since(a.readAt) >= tokenFileCheckInterval {
+		if _, err := a.read(); err != nil {
+			// keep using the current token, it may still be valid
+			log.Warn().Err(err).Str("file", a.file).Msg("Failed to read the Splunk access token")
+		}
+	}
+	a.warnBeforeExpiry()
+	request.SetHeader("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+// read reads the token from the file and reports whether it changed. The caller must hold the lock, except during
+// setup.
+func (a *tokenAuth) read() (bool, error) {
+	a.readAt = time.Now()
+	content, err := os.ReadFile(a.file)
+	if err != nil {
+		return false, fmt.Errorf("failed to read access token: %w", err)
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return false, fmt.Errorf("access token file %s is empty", a.file)
+	}
+	if token == a.token {
+		return false, nil
+	}
+	if a.token != "" {
+		log.Info().Str("file", a.file).Msg("Splunk access token changed, using the new one")
+	}
+	a.setToken(token)
+	return true, nil
+}
+
+func (a *tokenAuth) setToken(token string) {
+	a.token = token
+	a.expiryWarned = false
+	a.warnBeforeExpiry()
+}
+
+// warnBeforeExpiry warns once per token if it is a JWT expiring soon, e.g. because its rotation is broken.
+func (a *tokenAuth) warnBeforeExpiry() {
+	if a.expiryWarned {
+		return
+	}
+	expiresAt, ok := tokenExpiry(a.token)
+	if !ok {
+		return
+	}
+	remaining := time.Until(expiresAt)
+	if remaining > tokenExpiryWarningPeriod {
+		return
+	}
+	a.expiryWarned = true
+	if remaining <= 0 {
+		log.Error().Time("expiresAt", expiresAt).Msg("The Splunk access token has expired")
+	} else {
+		log.Warn().Time("expiresAt", expiresAt).Msgf("The Splunk access token expires in %s", remaining.Round(time.Minute))
+	}
+}
+
+// rereadRejectedToken reads the token file again if Splunk rejected the token, and retries if it changed.
+func (a *tokenAuth) rereadRejectedToken(res *resty.Response, _ error) bool {
+	if res == nil || res.StatusCode() != http.StatusUnauthorized {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if res.Request.Header.Get("Authorization") != "Bearer "+a.token {
+		// already changed by a concurrent request
+		return true
+	}
+	changed, err := a.read()
+	if err != nil {
+		log.Warn().Err(err).Str("file", a.file).Msg("Failed to read the Splunk access token")
+	}
+	return changed
+}
+
+// tokenExpiry returns the expiry of a JWT, as issued by Splunk for authentication tokens.
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
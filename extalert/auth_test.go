@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"encoding/base64"
+	"github.com/steadybit/extension-splunk-platform/config"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newSessionAuthServer(logins *atomic.Int32, validSessionKey *atomic.Value) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case loginPath:
+			_ = r.ParseForm()
+			if r.PostForm.Get("username") != "admin" || r.PostForm.Get("password") != "changeme" {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"messages":[{"type":"WARN","text":"Login failed"}]}`))
+				return
+			}
+			logins.Add(1)
+			_, _ = w.Write([]byte(`{"sessionKey":"` + validSessionKey.Load().(string) + `"}`))
+		default:
+			if r.Header.Get("Authorization") != "Splunk "+validSessionKey.Load().(string) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"paging":{"total":1},"entry":[{"name":"alert"}]}`))
+		}
+	}))
+}
+
+func TestSessionAuth_LogsInOnceAndReusesSessionKey(t *testing.T) {
+	var logins atomic.Int32
+	var sessionKey atomic.Value
+	sessionKey.Store("key-1")
+	srv := newSessionAuthServer(&logins, &sessionKey)
+	defer srv.Close()
+
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AuthMode: config.AuthModeBasic, Username: "admin", Password: "changeme"})
+	require.NoError(t, err)
+
+	for range 3 {
+		entries, err := c.Alerts(t.Context())
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	}
+	require.Equal(t, int32(1), logins.Load())
+}
+
+func TestSessionAuth_LogsInAgainOnExpiredSession(t *testing.T) {
+	var logins atomic.Int32
+	var sessionKey atomic.Value
+	sessionKey.Store("key-1")
+	srv := newSessionAuthServer(&logins, &sessionKey)
+	defer srv.Close()
+
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AuthMode: config.AuthModeBasic, Username: "admin", Password: "changeme"})
+	require.NoError(t, err)
+	_, err = c.Alerts(t.Context())
+	require.NoError(t, err)
+
+	// the session times out on the server
+	sessionKey.Store("key-2")
+
+	entries, err := c.Alerts(t.Context())
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, int32(2), logins.Load())
+}
+
+func TestSessionAuth_LoginFailure(t *testing.T) {
+	var logins atomic.Int32
+	var sessionKey atomic.Value
+	sessionKey.Store("key-1")
+	srv := newSessionAuthServer(&logins, &sessionKey)
+	defer srv.Close()
+
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AuthMode: config.AuthModeBasic, Username: "admin", Password: "wrong"})
+	require.NoError(t, err)
+	_, err = c.Alerts(t.Context())
+
+	require.ErrorContains(t, err, `failed to log in to Splunk as "admin": unexpected status code 401`)
+}
+
+func TestTokenAuth_SendsBearerToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"paging":{"total":0},"entry":[]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AuthMode: config.AuthModeToken, AccessToken: "token"})
+	require.NoError(t, err)
+	_, err = c.Alerts(t.Context())
+
+	require.NoError(t, err)
+}
+
+func TestTokenAuth_UsesRotatedTokenFromFile(t *testing.T) {
+	var validToken atomic.Value
+	validToken.Store("token-1")
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Authorization") != "Bearer "+validToken.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"paging":{"total":0},"entry":[]}`))
+	}))
+	defer srv.Close()
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token-1\n"), 0o600))
+
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AuthMode: config.AuthModeToken, AccessTokenFile: tokenFile})
+	require.NoError(t, err)
+	_, err = c.Alerts(t.Context())
+	require.NoError(t, err)
+
+	// the secret is rotated
+	validToken.Store("token-2")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token-2\n"), 0o600))
+
+	_, err = c.Alerts(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, int32(3), requests.Load(), "the rejected request is retried with the rotated token")
+}
+
+func TestTokenAuth_MissingTokenFile(t *testing.T) {
+	_, err := NewSplunkClient(config.Instance{AuthMode: config.AuthModeToken, AccessTokenFile: filepath.Join(t.TempDir(), "token")})
+
+	require.ErrorContains(t, err, "failed to read access token")
+}
+
+func TestTokenExpiry(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"iss":"admin from splunk","sub":"admin","aud":"steadybit","exp":1735725600}`))
+
+	expiresAt, ok := tokenExpiry("eyJraWQiOiJzcGx1bmsuc2VjcmV0IiwiYWxnIjoiSFM1MTIifQ." + payload + ".signature")
+	require.True(t, ok)
+	require.Equal(t, time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), expiresAt.UTC())
+
+	_, ok = tokenExpiry("not-a-jwt")
+	require.False(t, ok)
+}
@@ -4,22 +4,48 @@
 package extalert
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/action-kit/go/action_kit_sdk"
 	"github.com/steadybit/extension-kit/extbuild"
 	"github.com/steadybit/extension-kit/extutil"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type FiredAlertsClient interface {
-	FiredAlerts(ctx context.Context, alertUrl string) ([]Entry, error)
+	FiredAlertsSince(ctx context.Context, alertUrl string, since int64) ([]Entry, error)
+	LatestFiredAlert(ctx context.Context, alertUrl string) (*Entry, error)
+}
+
+type SearchResultsClient interface {
+	SearchResults(ctx context.Context, sid string) ([]map[string]any, error)
+}
+
+// FilteredFiredAlertsClient fetches fired alerts and the search results the fired alert filters may need.
+type FilteredFiredAlertsClient interface {
+	FiredAlertsClient
+	SearchResultsClient
+}
+
+type AlertCheckClient interface {
+	PreflightClient
+	FilteredFiredAlertsClient
+	SearchJobUrl(sid string) string
 }
 
 type AlertCheckAction struct {
-	Client FiredAlertsClient
+	Clients ClientResolver[AlertCheckClient]
 }
 
 var (
@@ -28,6 +54,7 @@ var (
 )
 
 type AlertCheckState struct {
+	Instance           string
 	Id                 string
 	Name               string
 	Url                string
@@ -42,19 +69,79 @@ type AlertCheckState struct {
 	// (FailEarly = false) so it can be reported once the step ends.
 	DeviationTitle string
 	TriggerTime    int64
+	// FireCount is the number of fired alerts expected by the 'At least N times' and 'At most N times' modes.
+	FireCount int
+	// MaxFiresPerMinute is the number of fired alerts allowed within any minute by the 'Fire rate' mode.
+	MaxFiresPerMinute int
+	// MaxDetectionTime is the time in milliseconds the alert may take to fire in the 'Time to fire' mode.
+	MaxDetectionTime int64
+	// DetectionTriggerTime is the trigger time of the first alert fired after the start of the step, 0 if none fired yet.
+	DetectionTriggerTime int64
+	// QuietPeriod is the time in milliseconds the alert must not fire before the end of the step in the 'Fired then cleared' mode.
+	QuietPeriod int64
+	// LatestTriggerTime is the latest trigger time of all alerts fired after the start of the step, 0 if none fired yet.
+	LatestTriggerTime int64
+	FiredAlertFilter
+	// FiredAlerts accumulates the alerts fired since the start of the step matching the filters, ordered by trigger time.
+	FiredAlerts []FiredAlert
+	// FiredAlertsCursor is the latest trigger time fetched so far. Only alerts fired at or after it are fetched again,
+	// so the history of the alert is not read on every poll.
+	FiredAlertsCursor int64
+	// PreviousTriggerTime is the trigger time of the newest alert fired before the step, if it matches the filters. The
+	// 'All the time' and 'At least once' modes consider it unless only new alerts are checked.
+	PreviousTriggerTime        int64
+	PreviousTriggerTimeFetched bool
+}
+
+// FiredAlert is the part of a fired alert the checks keep in their state.
+type FiredAlert struct {
+	TriggerTime     int64
+	Sid             string
+	Severity        Severity
+	TriggeredAlerts int
+}
+
+func newFiredAlert(entry Entry) FiredAlert {
+	return FiredAlert{
+		TriggerTime:     entry.Content.TriggerTime,
+		Sid:             entry.Content.Sid,
+		Severity:        entry.Content.AlertSeverity(),
+		TriggeredAlerts: int(entry.Content.TriggeredAlerts),
+	}
+}
+
+// FiredAlertFilter selects the fired alerts considered by the alert checks.
+type FiredAlertFilter struct {
+	// MinSeverity ignores fired alerts with a lower severity, 0 to consider all severities.
+	MinSeverity Severity
+	// MinTriggeredAlerts ignores fired alerts with fewer triggered results, 0 to consider all fired alerts.
+	MinTriggeredAlerts int
+	// ResultField and ResultValue ignore fired alerts without a search result having this field value, empty to
+	// consider all fired alerts.
+	ResultField string
+	ResultValue string
+	// ResultMatches caches by sid whether the search results of a fired alert matched ResultField and ResultValue.
+	ResultMatches map[string]bool
 }
 
 const (
 	alertFired    = "alertFired"
 	alertNotFired = "alertNotFired"
 
-	stateCheckModeAtLeastOnce = "atLeastOnce"
-	stateCheckModeAllTheTime  = "allTheTime"
+	stateCheckModeAtLeastOnce       = "atLeastOnce"
+	stateCheckModeAllTheTime        = "allTheTime"
+	stateCheckModeAtLeastNTimes     = "atLeastNTimes"
+	stateCheckModeAtMostNTimes      = "atMostNTimes"
+	stateCheckModeMaxFiresPerMinute = "maxFiresPerMinute"
+	stateCheckModeTimeToFire        = "timeToFire"
+	stateCheckModeFiredThenCleared  = "firedThenCleared"
+
+	metricNameTimeToFire = "splunk_alert_time_to_fire_seconds"
 )
 
-func NewAlertCheckAction(client FiredAlertsClient) action_kit_sdk.Action[AlertCheckState] {
+func NewAlertCheckAction(clients ClientResolver[AlertCheckClient]) action_kit_sdk.Action[AlertCheckState] {
 	return &AlertCheckAction{
-		Client: client,
+		Clients: clients,
 	}
 }
 
@@ -84,7 +171,7 @@ func (a *AlertCheckAction) Describe() action_kit_api.ActionDescription {
 		Category:    new("Monitoring"),
 		Kind:        action_kit_api.Check,
 		TimeControl: action_kit_api.TimeControlInternal,
-		Parameters: []action_kit_api.ActionParameter{
+		Parameters: append([]action_kit_api.ActionParameter{
 			{
 				Name:         "duration",
 				Label:        "Duration",
@@ -132,19 +219,73 @@ func (a *AlertCheckAction) Describe() action_kit_api.ActionDescription {
 						Label: "At least once",
 						Value: stateCheckModeAtLeastOnce,
 					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Fired at least N times",
+						Value: stateCheckModeAtLeastNTimes,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Fired at most N times",
+						Value: stateCheckModeAtMostNTimes,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Fired at most N times per minute",
+						Value: stateCheckModeMaxFiresPerMinute,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Fired within detection time",
+						Value: stateCheckModeTimeToFire,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Fired then cleared",
+						Value: stateCheckModeFiredThenCleared,
+					},
 				}),
 				Required: new(true),
 			},
+			{
+				Name:         "fireCount",
+				Label:        "Fire Count",
+				Description:  new("The number of times the alert should have fired since the start of the step. Only used by the 'Fired at least N times' and 'Fired at most N times' modes, which ignore the expected state."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: new("1"),
+				MinValue:     new(0),
+				Required:     new(false),
+			},
+			{
+				Name:         "maxFiresPerMinute",
+				Label:        "Max Fires per Minute",
+				Description:  new("The number of times the alert may fire within any minute. Only used by the 'Fired at most N times per minute' mode, which ignores the expected state."),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: new("1"),
+				MinValue:     new(0),
+				Required:     new(false),
+			},
+			{
+				Name:         "maxDetectionTime",
+				Label:        "Max Detection Time",
+				Description:  new("The time the alert may take to fire after the start of the step. Only used by the 'Fired within detection time' mode, which only considers alerts fired after the start of the step."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("5m"),
+				Required:     new(false),
+			},
+			{
+				Name:         "quietPeriod",
+				Label:        "Quiet Period",
+				Description:  new("The time before the end of the step in which the alert must not fire anymore. Only used by the 'Fired then cleared' mode, which only considers alerts fired after the start of the step."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("1m"),
+				Required:     new(false),
+			},
 			{
 				Name:         "failEarly",
 				Label:        "Fail early",
-				Description:  new("If enabled, the check fails as soon as a deviating state is observed. If disabled, the check keeps collecting events for the whole duration and only fails at the end of the step. Only affects the 'All the time' mode; 'At least once' can only be evaluated at the end of the step."),
+				Description:  new("If enabled, the check fails as soon as a deviating state is observed. If disabled, the check keeps collecting events for the whole duration and only fails at the end of the step. Only affects the 'All the time', 'at most', 'detection time' and 'cleared' modes; the other modes can only be evaluated at the end of the step."),
 				Type:         action_kit_api.ActionParameterTypeBoolean,
 				DefaultValue: new("true"),
 				Advanced:     new(true),
 				Required:     new(false),
 			},
-		},
+		}, firedAlertFilterParameters()...),
 		Widgets: new([]action_kit_api.Widget{
 			action_kit_api.StateOverTimeWidget{
 				Type:  action_kit_api.ComSteadybitWidgetStateOverTime,
@@ -172,14 +313,13 @@ func (a *AlertCheckAction) Describe() action_kit_api.ActionDescription {
 	}
 }
 
-func (a *AlertCheckAction) Prepare(_ context.Context, state *AlertCheckState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+func (a *AlertCheckAction) Prepare(ctx context.Context, state *AlertCheckState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	alertId := request.Target.Attributes[attributeID]
 	if len(alertId) == 0 {
 		return nil, fmt.Errorf("target is missing the id attribute")
 	}
 
 	start := time.Now()
-	end := start.Add(time.Duration(extutil.ToInt64(request.Config["duration"])) * time.Millisecond)
 
 	expectedState := extutil.ToString(request.Config["expectedState"])
 	if expectedState == "" {
@@ -191,6 +331,12 @@ func (a *AlertCheckAction) Prepare(_ context.Context, state *AlertCheckState, re
 		return nil, fmt.Errorf("expected state check mode parameter is missing")
 	}
 
+	duration := time.Duration(extutil.ToInt64(request.Config["duration"])) * time.Millisecond
+	quietPeriod := time.Duration(extutil.ToInt64(request.Config["quietPeriod"])) * time.Millisecond
+	if stateCheckMode == stateCheckModeFiredThenCleared && quietPeriod > duration {
+		return nil, fmt.Errorf("quiet period of %s must not be longer than the step duration of %s", quietPeriod, duration)
+	}
+
 	checkNewAlertsOnly := extutil.ToBool(request.Config["checkNewAlertsOnly"])
 
 	alertName := request.Target.Attributes[attributeName]
@@ -202,54 +348,81 @@ func (a *AlertCheckAction) Prepare(_ context.Context, state *AlertCheckState, re
 		return nil, fmt.Errorf("target is missing the url attribute")
 	}
 
+	if instance := request.Target.Attributes[attributeInstance]; len(instance) > 0 {
+		state.Instance = instance[0]
+	}
 	state.Id = alertId[0]
 	state.Name = alertName[0]
 	state.Url = alertUrl[0]
 	state.CheckNewAlertsOnly = checkNewAlertsOnly
 	state.Start = start
-	state.End = end
+	state.End = start.Add(duration)
 	state.ExpectedState = expectedState
 	state.StateCheckMode = stateCheckMode
+	state.FireCount = extutil.ToInt(request.Config["fireCount"])
+	state.MaxFiresPerMinute = extutil.ToInt(request.Config["maxFiresPerMinute"])
+	state.MaxDetectionTime = extutil.ToInt64(request.Config["maxDetectionTime"])
+	state.QuietPeriod = quietPeriod.Milliseconds()
+	state.FiredAlertFilter = newFiredAlertFilter(request.Config)
+	// the history of the alert is not fetched, only the newest alert fired before the step if the mode considers it
+	state.FiredAlertsCursor = start.Unix()
 	// Default to failing early to preserve the previous behavior for experiments that don't set this parameter.
 	state.FailEarly = true
 	if request.Config["failEarly"] != nil {
 		state.FailEarly = extutil.ToBool(request.Config["failEarly"])
 	}
 
+	capabilities := []string{capabilityListSavedSearches}
+	if state.ResultField != "" {
+		capabilities = append(capabilities, capabilitySearch)
+	}
+	if err := preflight(ctx, a.Clients, state.Instance, capabilities...); err != nil {
+		return nil, err
+	}
+
 	log.Trace().Any("state", state).Msg("check action state")
 
 	return nil, nil
 }
 
 func (a *AlertCheckAction) Start(ctx context.Context, state *AlertCheckState) (*action_kit_api.StartResult, error) {
-	statusResult, err := checkFiredAlerts(ctx, state, a.Client)
-	if statusResult == nil {
+	client, err := a.Clients(state.Instance)
+	if err != nil {
 		return nil, err
 	}
+	statusResult, err := checkFiredAlerts(ctx, state, client)
+	if statusResult == nil {
+		return nil, toExtensionError(err)
+	}
 	return &action_kit_api.StartResult{
 		Artifacts: statusResult.Artifacts,
 		Error:     statusResult.Error,
 		Messages:  statusResult.Messages,
 		Metrics:   statusResult.Metrics,
-	}, err
+	}, toExtensionError(err)
 }
 
 func (a *AlertCheckAction) Status(ctx context.Context, state *AlertCheckState) (*action_kit_api.StatusResult, error) {
-	return checkFiredAlerts(ctx, state, a.Client)
+	client, err := a.Clients(state.Instance)
+	if err != nil {
+		return nil, err
+	}
+	statusResult, err := checkFiredAlerts(ctx, state, client)
+	return statusResult, toExtensionError(err)
 }
 
-func checkFiredAlerts(ctx context.Context, state *AlertCheckState, client FiredAlertsClient) (*action_kit_api.StatusResult, error) {
+func checkFiredAlerts(ctx context.Context, state *AlertCheckState, client AlertCheckClient) (*action_kit_api.StatusResult, error) {
 	now := time.Now()
 
-	allFiredAlerts, err := client.FiredAlerts(ctx, state.Url)
-	if err != nil {
+	if err := fetchFiredAlerts(ctx, state, client); err != nil {
 		return nil, err
 	}
+	allFiredAlerts := state.FiredAlerts
 
-	var firedAlerts []Entry
+	var firedAlerts []FiredAlert
 	if state.CheckNewAlertsOnly {
 		for _, firedAlert := range allFiredAlerts {
-			if firedAlert.Content.TriggerTime > state.Start.Unix() {
+			if firedAfterStart(state.Start, firedAlert.TriggerTime) {
 				firedAlerts = append(firedAlerts, firedAlert)
 			}
 		}
@@ -257,46 +430,352 @@ func checkFiredAlerts(ctx context.Context, state *AlertCheckState, client FiredA
 		firedAlerts = allFiredAlerts
 	}
 
+	latest := latestTriggerTime(firedAlerts)
+	if !state.CheckNewAlertsOnly {
+		latest = max(latest, state.PreviousTriggerTime)
+	}
+
 	completed := now.After(state.End)
+	metrics := toMetrics(state.Id, state.Name, latest, now)
+	var messages []action_kit_api.Message
 	var checkError *action_kit_api.ActionKitError
-	if state.StateCheckMode == stateCheckModeAllTheTime {
-		deviation := checkAllTheTime(state, firedAlerts)
-		if deviation != nil {
-			if state.FailEarly {
-				// Fail as soon as the condition is violated.
-				checkError = deviation
-			} else if state.DeviationTitle == "" {
-				// Remember the first deviation to report it at the end of the step.
-				state.DeviationTitle = deviation.Title
-			}
-		}
-		if !state.FailEarly && completed && state.DeviationTitle != "" {
-			checkError = new(action_kit_api.ActionKitError{
-				Title:  state.DeviationTitle,
-				Status: extutil.Ptr(action_kit_api.Failed),
+	switch state.StateCheckMode {
+	case stateCheckModeAllTheTime:
+		checkError = reportDeviation(state, checkAllTheTime(state, latest), completed)
+	case stateCheckModeAtLeastOnce:
+		checkError = checkAtLeastOnce(state, completed, latest)
+	case stateCheckModeAtLeastNTimes:
+		checkError = checkAtLeastNTimes(state, completed, firedAlerts)
+	case stateCheckModeAtMostNTimes:
+		checkError = reportDeviation(state, checkAtMostNTimes(state, firedAlerts), completed)
+	case stateCheckModeMaxFiresPerMinute:
+		checkError = reportDeviation(state, checkMaxFiresPerMinute(state, firedAlerts), completed)
+	case stateCheckModeFiredThenCleared:
+		checkError = checkFiredThenCleared(state, completed, allFiredAlerts)
+	case stateCheckModeTimeToFire:
+		var detected bool
+		detected, checkError = checkTimeToFire(state, completed, now, allFiredAlerts)
+		if detected {
+			// report the measured detection time once, when the alert is first observed
+			timeToFire := detectionTime(state)
+			messages = append(messages, action_kit_api.Message{
+				Message: fmt.Sprintf("Alert %q fired %s after the start of the step.", state.Name, timeToFire),
+				Level:   extutil.Ptr(action_kit_api.Info),
+			})
+			metrics = append(metrics, action_kit_api.Metric{
+				Name: new(metricNameTimeToFire),
+				Metric: map[string]string{
+					metricLabel: state.Name,
+				},
+				Timestamp: now,
+				Value:     timeToFire.Seconds(),
 			})
 		}
-	} else if state.StateCheckMode == stateCheckModeAtLeastOnce {
-		checkError = checkAtLeastOnce(state, completed, firedAlerts)
 	}
 
-	return &action_kit_api.StatusResult{
+	result := &action_kit_api.StatusResult{
 		Completed: completed,
 		Error:     checkError,
-		Metrics:   new(toMetrics(state.Name, firedAlerts, now)),
+		Metrics:   new(metrics),
+	}
+	if len(messages) > 0 {
+		result.Messages = new(messages)
+	}
+	if completed || checkError != nil {
+		artifacts, err := toArtifacts(firedAlerts, client.SearchJobUrl)
+		if err != nil {
+			return nil, err
+		}
+		result.Artifacts = new(artifacts)
+	}
+	return result, nil
+}
+
+// fetchFiredAlerts adds the alerts fired since the cursor to the state. Alerts fired within the second of the cursor
+// are fetched again, as further alerts may have fired in that second after the previous poll.
+func fetchFiredAlerts(ctx context.Context, state *AlertCheckState, client AlertCheckClient) error {
+	if !state.PreviousTriggerTimeFetched && !state.CheckNewAlertsOnly &&
+		(state.StateCheckMode == stateCheckModeAllTheTime || state.StateCheckMode == stateCheckModeAtLeastOnce) {
+		previousTriggerTime, err := fetchPreviousTriggerTime(ctx, client, &state.FiredAlertFilter, state.Url, state.Start)
+		if err != nil {
+			return err
+		}
+		state.PreviousTriggerTime = previousTriggerTime
+		state.PreviousTriggerTimeFetched = true
+	}
+
+	fetchedAlerts, err := client.FiredAlertsSince(ctx, state.Url, state.FiredAlertsCursor)
+	if err != nil {
+		return err
+	}
+
+	// only the alerts of the cursor second can have been fetched before
+	known := make(map[FiredAlert]bool)
+	for i := len(state.FiredAlerts) - 1; i >= 0 && state.FiredAlerts[i].TriggerTime >= state.FiredAlertsCursor; i-- {
+		known[state.FiredAlerts[i]] = true
+	}
+	var newFiredAlerts []FiredAlert
+	for _, entry := range fetchedAlerts {
+		firedAlert := newFiredAlert(entry)
+		state.FiredAlertsCursor = max(state.FiredAlertsCursor, firedAlert.TriggerTime)
+		if !known[firedAlert] && firedAfterStart(state.Start, firedAlert.TriggerTime) {
+			newFiredAlerts = append(newFiredAlerts, firedAlert)
+		}
+	}
+
+	newFiredAlerts, err = state.FiredAlertFilter.filter(ctx, client, newFiredAlerts)
+	if err != nil {
+		return err
+	}
+	state.FiredAlerts = append(state.FiredAlerts, newFiredAlerts...)
+	sortFiredAlerts(state.FiredAlerts)
+	return nil
+}
+
+// fetchPreviousTriggerTime returns the trigger time of the newest alert not fired after the start of the step, 0 if
+// there is none or it doesn't match the filters. Only the newest alert is fetched instead of the history of the alert.
+func fetchPreviousTriggerTime(ctx context.Context, client FilteredFiredAlertsClient, filter *FiredAlertFilter, alertUrl string, start time.Time) (int64, error) {
+	entry, err := client.LatestFiredAlert(ctx, alertUrl)
+	if err != nil || entry == nil || firedAfterStart(start, entry.Content.TriggerTime) {
+		return 0, err
+	}
+	previous, err := filter.filter(ctx, client, []FiredAlert{newFiredAlert(*entry)})
+	if err != nil {
+		return 0, err
+	}
+	return latestTriggerTime(previous), nil
+}
+
+func sortFiredAlerts(firedAlerts []FiredAlert) {
+	slices.SortStableFunc(firedAlerts, func(a, b FiredAlert) int {
+		return cmp.Compare(a.TriggerTime, b.TriggerTime)
+	})
+}
+
+// latestTriggerTime returns the trigger time of the newest fired alert, 0 if none fired. The fired alerts are ordered by
+// trigger time.
+func latestTriggerTime(firedAlerts []FiredAlert) int64 {
+	if len(firedAlerts) == 0 {
+		return 0
+	}
+	return firedAlerts[len(firedAlerts)-1].TriggerTime
+}
+
+type firedAlertRecord struct {
+	TriggerTime     string `json:"triggerTime"`
+	Severity        string `json:"severity"`
+	Sid             string `json:"sid"`
+	TriggeredAlerts int    `json:"triggeredAlerts"`
+	JobUrl          string `json:"jobUrl"`
+}
+
+// toArtifacts renders the fired alerts as JSON and CSV, so they can be analyzed without access to Splunk.
+func toArtifacts(firedAlerts []FiredAlert, searchJobUrl func(sid string) string) ([]action_kit_api.Artifact, error) {
+	records := make([]firedAlertRecord, 0, len(firedAlerts))
+	for _, firedAlert := range firedAlerts {
+		records = append(records, firedAlertRecord{
+			TriggerTime:     time.Unix(firedAlert.TriggerTime, 0).UTC().Format(time.RFC3339),
+			Severity:        firedAlert.Severity.String(),
+			Sid:             firedAlert.Sid,
+			TriggeredAlerts: firedAlert.TriggeredAlerts,
+			JobUrl:          searchJobUrl(firedAlert.Sid),
+		})
+	}
+
+	jsonData, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render fired alerts as json: %w", err)
+	}
+
+	var csvData bytes.Buffer
+	writer := csv.NewWriter(&csvData)
+	_ = writer.Write([]string{"trigger_time", "severity", "sid", "triggered_alerts", "job_url"})
+	for _, record := range records {
+		_ = writer.Write([]string{record.TriggerTime, record.Severity, record.Sid, strconv.Itoa(record.TriggeredAlerts), record.JobUrl})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to render fired alerts as csv: %w", err)
+	}
+
+	return []action_kit_api.Artifact{
+		{
+			Label: "fired_alerts.json",
+			Data:  base64.StdEncoding.EncodeToString(jsonData),
+		},
+		{
+			Label: "fired_alerts.csv",
+			Data:  base64.StdEncoding.EncodeToString(csvData.Bytes()),
+		},
 	}, nil
 }
 
-func checkAllTheTime(state *AlertCheckState, firedAlerts []Entry) *action_kit_api.ActionKitError {
-	if state.ExpectedState == alertNotFired && len(firedAlerts) > 0 {
-		triggerTime := time.Unix(firedAlerts[0].Content.TriggerTime, 0).UTC().Format(time.RFC3339)
+// firedAlertFilterParameters are the parameters of the FiredAlertFilter.
+func firedAlertFilterParameters() []action_kit_api.ActionParameter {
+	return []action_kit_api.ActionParameter{
+		{
+			Name:         "minSeverity",
+			Label:        "Minimum Severity",
+			Description:  new("Only count fired alerts with at least this severity."),
+			Type:         action_kit_api.ActionParameterTypeString,
+			DefaultValue: new(""),
+			Options:      new(severityOptions()),
+			Advanced:     new(true),
+			Required:     new(false),
+		},
+		{
+			Name:         "minTriggeredAlerts",
+			Label:        "Minimum Triggered Results",
+			Description:  new("Only count fired alerts whose search triggered at least this many results. 0 counts all fired alerts."),
+			Type:         action_kit_api.ActionParameterTypeInteger,
+			DefaultValue: new("0"),
+			MinValue:     new(0),
+			Advanced:     new(true),
+			Required:     new(false),
+		},
+		{
+			Name:        "resultField",
+			Label:       "Result Field",
+			Description: new("Only count fired alerts whose search results contain a row with this field set to the result value, for example 'host'."),
+			Type:        action_kit_api.ActionParameterTypeString,
+			Advanced:    new(true),
+			Required:    new(false),
+		},
+		{
+			Name:        "resultValue",
+			Label:       "Result Value",
+			Description: new("The value the result field must have, for example 'web-01'. Only used if a result field is set."),
+			Type:        action_kit_api.ActionParameterTypeString,
+			Advanced:    new(true),
+			Required:    new(false),
+		},
+	}
+}
+
+func newFiredAlertFilter(config map[string]any) FiredAlertFilter {
+	return FiredAlertFilter{
+		MinSeverity:        Severity(extutil.ToInt(config["minSeverity"])),
+		MinTriggeredAlerts: extutil.ToInt(config["minTriggeredAlerts"]),
+		ResultField:        strings.TrimSpace(extutil.ToString(config["resultField"])),
+		ResultValue:        extutil.ToString(config["resultValue"]),
+	}
+}
+
+// filter drops the fired alerts not matching the severity, triggered results and result field filters.
+func (f *FiredAlertFilter) filter(ctx context.Context, client SearchResultsClient, allFiredAlerts []FiredAlert) ([]FiredAlert, error) {
+	var filtered []FiredAlert
+	for _, firedAlert := range allFiredAlerts {
+		if f.MinSeverity != 0 && firedAlert.Severity < f.MinSeverity {
+			continue
+		}
+		if firedAlert.TriggeredAlerts < f.MinTriggeredAlerts {
+			continue
+		}
+		if f.ResultField != "" {
+			matches, err := f.resultsMatch(ctx, client, firedAlert.Sid)
+			if err != nil {
+				return nil, err
+			}
+			if !matches {
+				continue
+			}
+		}
+		filtered = append(filtered, firedAlert)
+	}
+	return filtered, nil
+}
+
+// resultsMatch checks whether any search result of the fired alert's job has ResultField set to ResultValue. The
+// results of a fired alert don't change, so the outcome is remembered per sid.
+func (f *FiredAlertFilter) resultsMatch(ctx context.Context, client SearchResultsClient, sid string) (bool, error) {
+	if sid == "" {
+		return false, nil
+	}
+	if matches, ok := f.ResultMatches[sid]; ok {
+		return matches, nil
+	}
+
+	results, err := client.SearchResults(ctx, sid)
+	if errors.Is(err, ErrNotFound) {
+		// the search job of the fired alert expired, so there are no results left to match
+		log.Debug().Str("sid", sid).Msg("Search job of fired alert expired, treating it as not matching")
+		results = nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to check the search results of fired alert %s: %w", sid, err)
+	}
+	matches := slices.ContainsFunc(results, func(result map[string]any) bool {
+		return fieldHasValue(result[f.ResultField], f.ResultValue)
+	})
+
+	if f.ResultMatches == nil {
+		f.ResultMatches = make(map[string]bool)
+	}
+	f.ResultMatches[sid] = matches
+	return matches, nil
+}
+
+// fieldHasValue compares a result field with the expected value. Multivalue fields match if any of their values does.
+func fieldHasValue(field any, value string) bool {
+	switch v := field.(type) {
+	case nil:
+		return false
+	case []any:
+		return slices.ContainsFunc(v, func(item any) bool {
+			return fieldHasValue(item, value)
+		})
+	default:
+		return fmt.Sprint(v) == value
+	}
+}
+
+func severityOptions() []action_kit_api.ParameterOption {
+	options := []action_kit_api.ParameterOption{
+		action_kit_api.ExplicitParameterOption{
+			Label: "Any",
+			Value: "",
+		},
+	}
+	for severity := SeverityDebug; severity <= SeverityFatal; severity++ {
+		options = append(options, action_kit_api.ExplicitParameterOption{
+			Label: severity.String(),
+			Value: strconv.Itoa(int(severity)),
+		})
+	}
+	return options
+}
+
+// reportDeviation fails the check on a deviation right away if FailEarly is set. Otherwise, the first deviation is
+// remembered and reported once the step is completed.
+func reportDeviation(state *AlertCheckState, deviation *action_kit_api.ActionKitError, completed bool) *action_kit_api.ActionKitError {
+	if deviation != nil {
+		if state.FailEarly {
+			// Fail as soon as the condition is violated.
+			return deviation
+		} else if state.DeviationTitle == "" {
+			// Remember the first deviation to report it at the end of the step.
+			state.DeviationTitle = deviation.Title
+		}
+	}
+	if !state.FailEarly && completed && state.DeviationTitle != "" {
+		return new(action_kit_api.ActionKitError{
+			Title:  state.DeviationTitle,
+			Status: extutil.Ptr(action_kit_api.Failed),
+		})
+	}
+	return nil
+}
+
+// checkAllTheTime checks the trigger time of the newest fired alert, 0 if none fired.
+func checkAllTheTime(state *AlertCheckState, latestTriggerTime int64) *action_kit_api.ActionKitError {
+	if state.ExpectedState == alertNotFired && latestTriggerTime != 0 {
+		triggerTime := time.Unix(latestTriggerTime, 0).UTC().Format(time.RFC3339)
 		return new(action_kit_api.ActionKitError{
 			Title:  fmt.Sprintf("Alert %q should not have been fired but was at %s.", state.Name, triggerTime),
 			Status: extutil.Ptr(action_kit_api.Failed),
 		})
 	}
 
-	if state.ExpectedState == alertFired && len(firedAlerts) == 0 {
+	if state.ExpectedState == alertFired && latestTriggerTime == 0 {
 		return new(action_kit_api.ActionKitError{
 			Title:  fmt.Sprintf("Alert %q should have been fired all the time but was not.", state.Name),
 			Status: extutil.Ptr(action_kit_api.Failed),
@@ -306,14 +785,15 @@ func checkAllTheTime(state *AlertCheckState, firedAlerts []Entry) *action_kit_ap
 	return nil
 }
 
-func checkAtLeastOnce(state *AlertCheckState, completed bool, firedAlerts []Entry) *action_kit_api.ActionKitError {
-	if (state.ExpectedState == alertNotFired && len(firedAlerts) == 0) ||
-		(state.ExpectedState == alertFired && len(firedAlerts) > 0) {
+// checkAtLeastOnce checks the trigger time of the newest fired alert, 0 if none fired.
+func checkAtLeastOnce(state *AlertCheckState, completed bool, latestTriggerTime int64) *action_kit_api.ActionKitError {
+	if (state.ExpectedState == alertNotFired && latestTriggerTime == 0) ||
+		(state.ExpectedState == alertFired && latestTriggerTime != 0) {
 		state.StateCheckSuccess = true
 	}
 
-	if state.ExpectedState == alertNotFired && len(firedAlerts) > 0 && state.TriggerTime == 0 {
-		state.TriggerTime = firedAlerts[0].Content.TriggerTime
+	if state.ExpectedState == alertNotFired && latestTriggerTime != 0 && state.TriggerTime == 0 {
+		state.TriggerTime = latestTriggerTime
 	}
 
 	if completed && !state.StateCheckSuccess {
@@ -333,13 +813,141 @@ func checkAtLeastOnce(state *AlertCheckState, completed bool, firedAlerts []Entr
 	return nil
 }
 
-func toMetrics(alertName string, firedAlerts []Entry, now time.Time) []action_kit_api.Metric {
+func checkAtLeastNTimes(state *AlertCheckState, completed bool, firedAlerts []FiredAlert) *action_kit_api.ActionKitError {
+	if completed && len(firedAlerts) < state.FireCount {
+		return new(action_kit_api.ActionKitError{
+			Title:  fmt.Sprintf("Alert %q should have been fired at least %d times but was fired %d times.", state.Name, state.FireCount, len(firedAlerts)),
+			Status: extutil.Ptr(action_kit_api.Failed),
+		})
+	}
+	return nil
+}
+
+func checkAtMostNTimes(state *AlertCheckState, firedAlerts []FiredAlert) *action_kit_api.ActionKitError {
+	if len(firedAlerts) > state.FireCount {
+		return new(action_kit_api.ActionKitError{
+			Title:  fmt.Sprintf("Alert %q should have been fired at most %d times but was fired %d times.", state.Name, state.FireCount, len(firedAlerts)),
+			Status: extutil.Ptr(action_kit_api.Failed),
+		})
+	}
+	return nil
+}
+
+func checkMaxFiresPerMinute(state *AlertCheckState, firedAlerts []FiredAlert) *action_kit_api.ActionKitError {
+	fires, windowStart := maxFiresPerMinute(firedAlerts)
+	if fires > state.MaxFiresPerMinute {
+		return new(action_kit_api.ActionKitError{
+			Title: fmt.Sprintf("Alert %q should have been fired at most %d times per minute but was fired %d times in the minute after %s.",
+				state.Name, state.MaxFiresPerMinute, fires, time.Unix(windowStart, 0).UTC().Format(time.RFC3339)),
+			Status: extutil.Ptr(action_kit_api.Failed),
+		})
+	}
+	return nil
+}
+
+// checkTimeToFire fails if the first alert fired after the start of the step took longer than MaxDetectionTime.
+// detected is true for the call that first observed the alert firing.
+func checkTimeToFire(state *AlertCheckState, completed bool, now time.Time, allFiredAlerts []FiredAlert) (bool, *action_kit_api.ActionKitError) {
+	detected := false
+	if state.DetectionTriggerTime == 0 {
+		for _, firedAlert := range allFiredAlerts {
+			triggerTime := firedAlert.TriggerTime
+			if firedAfterStart(state.Start, triggerTime) && (state.DetectionTriggerTime == 0 || triggerTime < state.DetectionTriggerTime) {
+				state.DetectionTriggerTime = triggerTime
+			}
+		}
+		detected = state.DetectionTriggerTime != 0
+	}
+
+	maxDetectionTime := time.Duration(state.MaxDetectionTime) * time.Millisecond
+	var deviation *action_kit_api.ActionKitError
+	if state.DetectionTriggerTime != 0 {
+		if timeToFire := detectionTime(state); timeToFire > maxDetectionTime {
+			deviation = new(action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("Alert %q should have been fired within %s but took %s.", state.Name, maxDetectionTime, timeToFire),
+				Status: extutil.Ptr(action_kit_api.Failed),
+			})
+		}
+	} else if now.Sub(state.Start) > maxDetectionTime || completed {
+		deviation = new(action_kit_api.ActionKitError{
+			Title:  fmt.Sprintf("Alert %q should have been fired within %s but was not.", state.Name, maxDetectionTime),
+			Status: extutil.Ptr(action_kit_api.Failed),
+		})
+	}
+	return detected, reportDeviation(state, deviation, completed)
+}
+
+// checkFiredThenCleared requires the alert to fire after the start of the step and then to stay quiet for
+// QuietPeriod before the end of the step. A fire within the quiet period is a deviation that cannot recover anymore.
+func checkFiredThenCleared(state *AlertCheckState, completed bool, allFiredAlerts []FiredAlert) *action_kit_api.ActionKitError {
+	for _, firedAlert := range allFiredAlerts {
+		triggerTime := firedAlert.TriggerTime
+		if firedAfterStart(state.Start, triggerTime) && triggerTime > state.LatestTriggerTime {
+			state.LatestTriggerTime = triggerTime
+		}
+	}
+
+	quietPeriod := time.Duration(state.QuietPeriod) * time.Millisecond
+	quietSince := state.End.Add(-quietPeriod)
+	var deviation *action_kit_api.ActionKitError
+	if state.LatestTriggerTime != 0 && time.Unix(state.LatestTriggerTime, 0).After(quietSince) {
+		deviation = new(action_kit_api.ActionKitError{
+			Title: fmt.Sprintf("Alert %q should have been cleared %s before the end of the step but was fired at %s.",
+				state.Name, quietPeriod, time.Unix(state.LatestTriggerTime, 0).UTC().Format(time.RFC3339)),
+			Status: extutil.Ptr(action_kit_api.Failed),
+		})
+	} else if completed && state.LatestTriggerTime == 0 {
+		deviation = new(action_kit_api.ActionKitError{
+			Title:  fmt.Sprintf("Alert %q should have been fired and cleared but was not fired.", state.Name),
+			Status: extutil.Ptr(action_kit_api.Failed),
+		})
+	}
+	return reportDeviation(state, deviation, completed)
+}
+
+// firedAfterStart returns whether an alert fired after the start of the step. Splunk reports trigger times in whole
+// seconds, so alerts fired within the second the step started in can't be told apart from alerts fired before the step
+// and are not considered as fired after its start.
+func firedAfterStart(start time.Time, triggerTime int64) bool {
+	return triggerTime > start.Unix()
+}
+
+// detectionTime is the time from the start of the step to the detection, in whole seconds like the trigger time.
+func detectionTime(state *AlertCheckState) time.Duration {
+	return time.Unix(state.DetectionTriggerTime, 0).Sub(state.Start.Truncate(time.Second))
+}
+
+// maxFiresPerMinute returns the highest number of fired alerts within any sliding window of one minute, and the
+// trigger time starting that window.
+func maxFiresPerMinute(firedAlerts []FiredAlert) (int, int64) {
+	triggerTimes := make([]int64, 0, len(firedAlerts))
+	for _, firedAlert := range firedAlerts {
+		triggerTimes = append(triggerTimes, firedAlert.TriggerTime)
+	}
+	slices.Sort(triggerTimes)
+
+	maxFires, windowStart := 0, int64(0)
+	first := 0
+	for last := range triggerTimes {
+		for triggerTimes[last]-triggerTimes[first] >= 60 {
+			first++
+		}
+		if fires := last - first + 1; fires > maxFires {
+			maxFires, windowStart = fires, triggerTimes[first]
+		}
+	}
+	return maxFires, windowStart
+}
+
+// toMetrics reports the state of the alert, with the trigger time of its newest fired alert. A latestTriggerTime of 0
+// means the alert did not fire.
+func toMetrics(alertId string, alertName string, latestTriggerTime int64, now time.Time) []action_kit_api.Metric {
 	var triggerTime string
 	var tooltip string
 	var state string
 
-	if len(firedAlerts) > 0 {
-		triggerTime = time.Unix(firedAlerts[0].Content.TriggerTime, 0).UTC().Format(time.RFC3339)
+	if latestTriggerTime != 0 {
+		triggerTime = time.Unix(latestTriggerTime, 0).UTC().Format(time.RFC3339)
 		tooltip = fmt.Sprintf("Splunk Alert %q fired at %s", alertName, triggerTime)
 		state = "success"
 	}
@@ -348,7 +956,7 @@ func toMetrics(alertName string, firedAlerts []Entry, now time.Time) []action_ki
 		{
 			Name: new(fmt.Sprintf("Splunk Alert %s", alertName)),
 			Metric: map[string]string{
-				metricId:          alertName,
+				metricId:          alertId,
 				metricLabel:       alertName,
 				metricState:       state,
 				metricTooltip:     tooltip,
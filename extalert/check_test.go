@@ -4,17 +4,21 @@
 package extalert
 
 import (
+	"context"
+	"encoding/base64"
 	"errors"
 	"github.com/google/uuid"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-splunk-platform/config"
 	"github.com/stretchr/testify/require"
+	"net/http"
 	"strings"
 	"testing"
 	"time"
 )
 
 func TestAlertCheckAction_Describe_NoError(t *testing.T) {
-	action := NewAlertCheckAction(nil)
+	action := NewAlertCheckAction(resolveTo[AlertCheckClient](MockSplunkClient{}))
 
 	description := action.Describe()
 
@@ -22,16 +26,18 @@ func TestAlertCheckAction_Describe_NoError(t *testing.T) {
 }
 
 func TestAlertCheckAction_Prepare(t *testing.T) {
-	action := NewAlertCheckAction(nil)
+	var capabilities []string
+	action := NewAlertCheckAction(resolveTo[AlertCheckClient](MockSplunkClient{capabilities: &capabilities}))
 	state := action.NewEmptyState()
 	ctx := t.Context()
 
 	_, err := action.Prepare(ctx, &state, action_kit_api.PrepareActionRequestBody{
 		Target: &action_kit_api.Target{
 			Attributes: map[string][]string{
-				attributeID:   {"id"},
-				attributeName: {"name"},
-				attributeUrl:  {"url"},
+				attributeID:       {"id"},
+				attributeName:     {"name"},
+				attributeUrl:      {"url"},
+				attributeInstance: {"production"},
 			},
 		},
 		Config: map[string]any{
@@ -44,6 +50,8 @@ func TestAlertCheckAction_Prepare(t *testing.T) {
 	})
 
 	require.NoError(t, err)
+	require.Equal(t, []string{capabilityListSavedSearches}, capabilities)
+	require.Equal(t, "production", state.Instance)
 	require.Equal(t, "id", state.Id)
 	require.Equal(t, "id", state.Id)
 	require.Equal(t, "name", state.Name)
@@ -57,7 +65,7 @@ func TestAlertCheckAction_Prepare(t *testing.T) {
 }
 
 func TestAlertCheckAction_Start_NoError(t *testing.T) {
-	action := NewAlertCheckAction(MockSplunkClient{response: []Entry{}})
+	action := NewAlertCheckAction(resolveTo[AlertCheckClient](MockSplunkClient{response: []Entry{}}))
 	state := action.NewEmptyState()
 	ctx := t.Context()
 
@@ -558,3 +566,694 @@ func TestAlertCheckAction_checkFiredAlerts_error_on_lookup(t *testing.T) {
 
 	require.Error(t, err)
 }
+
+func firedAlertsAt(triggerTimes ...int64) []Entry {
+	var entries []Entry
+	for _, triggerTime := range triggerTimes {
+		entries = append(entries, Entry{Content: Content{TriggerTime: triggerTime}})
+	}
+	return entries
+}
+
+func TestAlertCheckAction_checkFiredAlerts_atLeastNTimes(t *testing.T) {
+	now := time.Now()
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          time.Unix(946684700, 0),
+		End:            now.Add(1 * time.Minute),
+		StateCheckMode: stateCheckModeAtLeastNTimes,
+		FireCount:      3,
+	}
+	mockClient := MockSplunkClient{response: firedAlertsAt(946684800, 946684810)}
+
+	result, err := checkFiredAlerts(t.Context(), &state, mockClient)
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.Nil(t, result.Error)
+
+	state.End = now.Add(-1 * time.Minute)
+	result, err = checkFiredAlerts(t.Context(), &state, mockClient)
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Alert "Alert Name" should have been fired at least 3 times but was fired 2 times.`, result.Error.Title)
+
+	mockClient.response = firedAlertsAt(946684800, 946684810, 946684820)
+	result, err = checkFiredAlerts(t.Context(), &state, mockClient)
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_atMostNTimes_failEarly(t *testing.T) {
+	now := time.Now()
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          time.Unix(946684700, 0),
+		End:            now.Add(1 * time.Minute),
+		StateCheckMode: stateCheckModeAtMostNTimes,
+		FireCount:      1,
+		FailEarly:      true,
+	}
+
+	result, err := checkFiredAlerts(t.Context(), &state, MockSplunkClient{response: firedAlertsAt(946684800)})
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+
+	result, err = checkFiredAlerts(t.Context(), &state, MockSplunkClient{response: firedAlertsAt(946684800, 946684900)})
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Alert "Alert Name" should have been fired at most 1 times but was fired 2 times.`, result.Error.Title)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_atMostNTimes_failAtEnd(t *testing.T) {
+	now := time.Now()
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          time.Unix(946684700, 0),
+		End:            now.Add(1 * time.Minute),
+		StateCheckMode: stateCheckModeAtMostNTimes,
+		FireCount:      0,
+		FailEarly:      false,
+	}
+	mockClient := MockSplunkClient{response: firedAlertsAt(946684800)}
+
+	result, err := checkFiredAlerts(t.Context(), &state, mockClient)
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+	require.NotEmpty(t, state.DeviationTitle)
+
+	state.End = now.Add(-1 * time.Second)
+	result, err = checkFiredAlerts(t.Context(), &state, mockClient)
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+	require.NotNil(t, result.Error)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_maxFiresPerMinute(t *testing.T) {
+	now := time.Now()
+	state := AlertCheckState{
+		Name:              "Alert Name",
+		Start:             time.Unix(946684700, 0),
+		End:               now.Add(1 * time.Minute),
+		StateCheckMode:    stateCheckModeMaxFiresPerMinute,
+		MaxFiresPerMinute: 2,
+		FailEarly:         true,
+	}
+
+	// three fires, but never more than two within a minute
+	result, err := checkFiredAlerts(t.Context(), &state, MockSplunkClient{response: firedAlertsAt(946684800, 946684830, 946684860)})
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+
+	// a flapping alert, checked from scratch
+	state.FiredAlerts, state.FiredAlertsCursor = nil, 0
+	result, err = checkFiredAlerts(t.Context(), &state, MockSplunkClient{response: firedAlertsAt(946684800, 946684900, 946684910, 946684920)})
+	require.NoError(t, err)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Alert "Alert Name" should have been fired at most 2 times per minute but was fired 3 times in the minute after 2000-01-01T00:01:40Z.`, result.Error.Title)
+}
+
+func TestMaxFiresPerMinute(t *testing.T) {
+	fires, _ := maxFiresPerMinute(nil)
+	require.Equal(t, 0, fires)
+
+	fires, windowStart := maxFiresPerMinute([]FiredAlert{{TriggerTime: 300}, {TriggerTime: 100}, {TriggerTime: 159}, {TriggerTime: 160}, {TriggerTime: 200}})
+	require.Equal(t, 3, fires)
+	require.Equal(t, int64(159), windowStart)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_timeToFire_withinDetectionTime(t *testing.T) {
+	start := time.Now().Add(-30 * time.Second).Truncate(time.Second)
+	state := AlertCheckState{
+		Name:             "Alert Name",
+		Start:            start,
+		End:              start.Add(2 * time.Minute),
+		StateCheckMode:   stateCheckModeTimeToFire,
+		MaxDetectionTime: 60_000,
+		FailEarly:        true,
+	}
+	// an alert fired before the step started does not count as detection
+	mockClient := MockSplunkClient{response: firedAlertsAt(start.Add(-1*time.Hour).Unix(), start.Add(20*time.Second).Unix(), start.Add(10*time.Second).Unix())}
+
+	result, err := checkFiredAlerts(t.Context(), &state, mockClient)
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+	require.Equal(t, start.Add(10*time.Second).Unix(), state.DetectionTriggerTime)
+	require.NotNil(t, result.Messages)
+	require.Equal(t, `Alert "Alert Name" fired 10s after the start of the step.`, (*result.Messages)[0].Message)
+	require.Len(t, *result.Metrics, 2)
+	require.Equal(t, 10.0, (*result.Metrics)[1].Value)
+
+	// the detection is reported only once
+	result, err = checkFiredAlerts(t.Context(), &state, mockClient)
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+	require.Nil(t, result.Messages)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_timeToFire_startSecond(t *testing.T) {
+	start := time.Now().Add(-30 * time.Second).Truncate(time.Second).Add(700 * time.Millisecond)
+	state := AlertCheckState{
+		Name:             "Alert Name",
+		Start:            start,
+		End:              start.Add(2 * time.Minute),
+		StateCheckMode:   stateCheckModeTimeToFire,
+		MaxDetectionTime: 60_000,
+		FailEarly:        true,
+	}
+
+	// an alert fired within the second the step started in may have fired before the step
+	result, err := checkFiredAlerts(t.Context(), &state, MockSplunkClient{response: firedAlertsAt(start.Unix())})
+	require.NoError(t, err)
+	require.Zero(t, state.DetectionTriggerTime)
+	require.Nil(t, result.Messages)
+
+	result, err = checkFiredAlerts(t.Context(), &state, MockSplunkClient{response: firedAlertsAt(start.Unix()+1, start.Unix())})
+	require.NoError(t, err)
+	require.Equal(t, start.Unix()+1, state.DetectionTriggerTime)
+	require.Equal(t, `Alert "Alert Name" fired 1s after the start of the step.`, (*result.Messages)[0].Message)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_timeToFire_tooSlow(t *testing.T) {
+	start := time.Now().Add(-90 * time.Second).Truncate(time.Second)
+	state := AlertCheckState{
+		Name:             "Alert Name",
+		Start:            start,
+		End:              start.Add(5 * time.Minute),
+		StateCheckMode:   stateCheckModeTimeToFire,
+		MaxDetectionTime: 60_000,
+		FailEarly:        true,
+	}
+
+	result, err := checkFiredAlerts(t.Context(), &state, MockSplunkClient{response: firedAlertsAt(start.Add(75 * time.Second).Unix())})
+
+	require.NoError(t, err)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Alert "Alert Name" should have been fired within 1m0s but took 1m15s.`, result.Error.Title)
+	require.NotNil(t, result.Messages)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_timeToFire_notFired(t *testing.T) {
+	start := time.Now().Add(-30 * time.Second)
+	state := AlertCheckState{
+		Name:             "Alert Name",
+		Start:            start,
+		End:              start.Add(5 * time.Minute),
+		StateCheckMode:   stateCheckModeTimeToFire,
+		MaxDetectionTime: 60_000,
+		FailEarly:        true,
+	}
+	mockClient := MockSplunkClient{response: []Entry{}}
+
+	result, err := checkFiredAlerts(t.Context(), &state, mockClient)
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+
+	// the detection time has passed without the alert firing
+	state.Start = start.Add(-1 * time.Minute)
+	result, err = checkFiredAlerts(t.Context(), &state, mockClient)
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Alert "Alert Name" should have been fired within 1m0s but was not.`, result.Error.Title)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_firedThenCleared(t *testing.T) {
+	start := time.Now().Add(-10 * time.Minute)
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          start,
+		End:            start.Add(15 * time.Minute),
+		StateCheckMode: stateCheckModeFiredThenCleared,
+		QuietPeriod:    120_000,
+		FailEarly:      true,
+	}
+	mockClient := MockSplunkClient{response: firedAlertsAt(start.Add(-1*time.Hour).Unix(), start.Add(1*time.Minute).Unix(), start.Add(3*time.Minute).Unix())}
+
+	result, err := checkFiredAlerts(t.Context(), &state, mockClient)
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+	require.Equal(t, start.Add(3*time.Minute).Unix(), state.LatestTriggerTime)
+
+	state.End = time.Now().Add(-1 * time.Second)
+	result, err = checkFiredAlerts(t.Context(), &state, mockClient)
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+	require.Nil(t, result.Error)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_firedThenCleared_firedInQuietPeriod(t *testing.T) {
+	start := time.Now().Add(-5 * time.Minute)
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          start,
+		End:            start.Add(6 * time.Minute),
+		StateCheckMode: stateCheckModeFiredThenCleared,
+		QuietPeriod:    120_000,
+		FailEarly:      true,
+	}
+	triggerTime := start.Add(4*time.Minute + 30*time.Second).Truncate(time.Second)
+
+	result, err := checkFiredAlerts(t.Context(), &state, MockSplunkClient{response: firedAlertsAt(start.Add(1*time.Minute).Unix(), triggerTime.Unix())})
+
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Alert "Alert Name" should have been cleared 2m0s before the end of the step but was fired at `+triggerTime.UTC().Format(time.RFC3339)+".", result.Error.Title)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_firedThenCleared_startSecond(t *testing.T) {
+	start := time.Now().Add(-10 * time.Minute).Truncate(time.Second).Add(700 * time.Millisecond)
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          start,
+		End:            time.Now().Add(-1 * time.Second),
+		StateCheckMode: stateCheckModeFiredThenCleared,
+		QuietPeriod:    60_000,
+		FailEarly:      true,
+	}
+
+	result, err := checkFiredAlerts(t.Context(), &state, MockSplunkClient{response: firedAlertsAt(start.Unix())})
+
+	require.NoError(t, err)
+	require.Zero(t, state.LatestTriggerTime)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Alert "Alert Name" should have been fired and cleared but was not fired.`, result.Error.Title)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_firedThenCleared_neverFired(t *testing.T) {
+	start := time.Now().Add(-5 * time.Minute)
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          start,
+		End:            start.Add(1 * time.Minute),
+		StateCheckMode: stateCheckModeFiredThenCleared,
+		QuietPeriod:    30_000,
+		FailEarly:      true,
+	}
+
+	result, err := checkFiredAlerts(t.Context(), &state, MockSplunkClient{response: firedAlertsAt(start.Add(-1 * time.Minute).Unix())})
+
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Alert "Alert Name" should have been fired and cleared but was not fired.`, result.Error.Title)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_minSeverity(t *testing.T) {
+	now := time.Now()
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          now.Add(-2 * time.Minute),
+		End:            now.Add(-1 * time.Second),
+		ExpectedState:  alertFired,
+		StateCheckMode: stateCheckModeAtLeastOnce,
+		FiredAlertFilter: FiredAlertFilter{
+			MinSeverity: SeveritySevere,
+		},
+	}
+	mockClient := MockSplunkClient{
+		response: []Entry{
+			{Content: Content{TriggerTime: now.Add(-1 * time.Minute).Unix(), FiredSeverity: SeverityInfo}},
+		},
+	}
+
+	result, err := checkFiredAlerts(t.Context(), &state, mockClient)
+
+	require.NoError(t, err)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Alert "Alert Name" should have been fired but was not.`, result.Error.Title)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_minSeverity_fallsBackToAlertSeverity(t *testing.T) {
+	now := time.Now()
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          now.Add(-2 * time.Minute),
+		End:            now.Add(-1 * time.Second),
+		ExpectedState:  alertFired,
+		StateCheckMode: stateCheckModeAtLeastOnce,
+		FiredAlertFilter: FiredAlertFilter{
+			MinSeverity: SeveritySevere,
+		},
+	}
+	mockClient := MockSplunkClient{
+		response: []Entry{
+			{Content: Content{TriggerTime: now.Add(-1 * time.Minute).Unix(), Severity: SeverityFatal}},
+		},
+	}
+
+	result, err := checkFiredAlerts(t.Context(), &state, mockClient)
+
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_minTriggeredAlerts(t *testing.T) {
+	now := time.Now()
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          now.Add(-2 * time.Minute),
+		End:            now.Add(-1 * time.Second),
+		StateCheckMode: stateCheckModeAtLeastNTimes,
+		FireCount:      2,
+		FiredAlertFilter: FiredAlertFilter{
+			MinTriggeredAlerts: 5,
+		},
+	}
+	mockClient := MockSplunkClient{
+		response: []Entry{
+			{Content: Content{TriggerTime: now.Add(-90 * time.Second).Unix(), TriggeredAlerts: 10}},
+			{Content: Content{TriggerTime: now.Add(-60 * time.Second).Unix(), TriggeredAlerts: 1}},
+			{Content: Content{TriggerTime: now.Add(-30 * time.Second).Unix(), TriggeredAlerts: 5}},
+		},
+	}
+
+	result, err := checkFiredAlerts(t.Context(), &state, mockClient)
+
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+
+	state.MinTriggeredAlerts = 6
+	state.FiredAlerts, state.FiredAlertsCursor = nil, 0
+	result, err = checkFiredAlerts(t.Context(), &state, mockClient)
+
+	require.NoError(t, err)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Alert "Alert Name" should have been fired at least 2 times but was fired 1 times.`, result.Error.Title)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_resultField(t *testing.T) {
+	now := time.Now()
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          now.Add(-2 * time.Minute),
+		End:            now.Add(-1 * time.Second),
+		StateCheckMode: stateCheckModeAtLeastNTimes,
+		FireCount:      2,
+		FiredAlertFilter: FiredAlertFilter{
+			ResultField: "host",
+			ResultValue: "web-01",
+		},
+	}
+	mockClient := MockSplunkClient{
+		response: []Entry{
+			{Content: Content{TriggerTime: now.Add(-90 * time.Second).Unix(), Sid: "sid-1"}},
+			{Content: Content{TriggerTime: now.Add(-60 * time.Second).Unix(), Sid: "sid-2"}},
+			{Content: Content{TriggerTime: now.Add(-30 * time.Second).Unix(), Sid: "sid-3"}},
+		},
+		results: map[string][]map[string]any{
+			"sid-1": {{"host": "db-01"}, {"host": "web-01"}},
+			"sid-2": {{"host": "db-01"}},
+			"sid-3": {{"host": []any{"web-02", "web-01"}}},
+		},
+	}
+
+	result, err := checkFiredAlerts(t.Context(), &state, mockClient)
+
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+	require.Equal(t, map[string]bool{"sid-1": true, "sid-2": false, "sid-3": true}, state.ResultMatches)
+
+	// the matches are cached per sid, the results are not fetched again
+	mockClient.results = nil
+	result, err = checkFiredAlerts(t.Context(), &state, mockClient)
+
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_resultField_error(t *testing.T) {
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          time.Now(),
+		End:            time.Now().Add(1 * time.Minute),
+		StateCheckMode: stateCheckModeAtLeastOnce,
+		FiredAlertFilter: FiredAlertFilter{
+			ResultField: "host",
+		},
+	}
+	mockClient := failingResultsClient{MockSplunkClient{response: []Entry{{Content: Content{Sid: "sid-1"}}}}}
+
+	_, err := checkFiredAlerts(t.Context(), &state, mockClient)
+
+	require.ErrorContains(t, err, "failed to check the search results of fired alert sid-1")
+}
+
+func TestAlertCheckAction_checkFiredAlerts_resultField_expiredJob(t *testing.T) {
+	now := time.Now()
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          now.Add(-2 * time.Minute),
+		End:            now.Add(-1 * time.Second),
+		StateCheckMode: stateCheckModeAtLeastOnce,
+		ExpectedState:  alertFired,
+		FiredAlertFilter: FiredAlertFilter{
+			ResultField: "host",
+			ResultValue: "web-01",
+		},
+	}
+	mockClient := MockSplunkClient{
+		response: []Entry{{Content: Content{TriggerTime: now.Add(-1 * time.Minute).Unix(), Sid: "expired"}}},
+	}
+
+	result, err := checkFiredAlerts(t.Context(), &state, expiredResultsClient{mockClient})
+
+	require.NoError(t, err)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Alert "Alert Name" should have been fired but was not.`, result.Error.Title)
+	require.Equal(t, map[string]bool{"expired": false}, state.ResultMatches)
+}
+
+type expiredResultsClient struct {
+	MockSplunkClient
+}
+
+func (c expiredResultsClient) SearchResults(_ context.Context, _ string) ([]map[string]any, error) {
+	return nil, newSplunkError(http.StatusNotFound, []byte(`{"messages":[{"type":"FATAL","text":"Unknown sid."}]}`))
+}
+
+type failingResultsClient struct {
+	MockSplunkClient
+}
+
+func (c failingResultsClient) SearchResults(_ context.Context, _ string) ([]map[string]any, error) {
+	return nil, errors.New("results expired")
+}
+
+func TestAlertCheckAction_checkFiredAlerts_artifacts(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	state := AlertCheckState{
+		Instance:       "splunk",
+		Name:           "Alert Name",
+		Start:          start,
+		End:            time.Now().Add(1 * time.Minute),
+		ExpectedState:  alertFired,
+		StateCheckMode: stateCheckModeAtLeastOnce,
+	}
+	mockClient := MockSplunkClient{
+		response: []Entry{
+			{Content: Content{TriggerTime: start.Add(2 * time.Minute).Unix(), FiredSeverity: SeveritySevere, Sid: "sid-2", TriggeredAlerts: 4}},
+			{Content: Content{TriggerTime: start.Add(1 * time.Minute).Unix(), FiredSeverity: SeverityInfo, Sid: "sid-1", TriggeredAlerts: 1}},
+		},
+		webBaseUrl: "https://splunk:8000",
+	}
+
+	result, err := checkFiredAlerts(t.Context(), &state, mockClient)
+	require.NoError(t, err)
+	require.Nil(t, result.Artifacts, "artifacts are only attached once the check is done")
+
+	// observing the same alerts again must not duplicate them
+	state.End = time.Now().Add(-1 * time.Second)
+	result, err = checkFiredAlerts(t.Context(), &state, mockClient)
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+	require.NotNil(t, result.Artifacts)
+	require.Len(t, *result.Artifacts, 2)
+
+	artifacts := *result.Artifacts
+	require.Equal(t, "fired_alerts.json", artifacts[0].Label)
+	jsonData, err := base64.StdEncoding.DecodeString(artifacts[0].Data)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"triggerTime":"2025-01-01T10:01:00Z","severity":"Info","sid":"sid-1","triggeredAlerts":1,"jobUrl":"https://splunk:8000/app/search/search?sid=sid-1"},
+		{"triggerTime":"2025-01-01T10:02:00Z","severity":"Severe","sid":"sid-2","triggeredAlerts":4,"jobUrl":"https://splunk:8000/app/search/search?sid=sid-2"}
+	]`, string(jsonData))
+
+	require.Equal(t, "fired_alerts.csv", artifacts[1].Label)
+	csvData, err := base64.StdEncoding.DecodeString(artifacts[1].Data)
+	require.NoError(t, err)
+	require.Equal(t, "trigger_time,severity,sid,triggered_alerts,job_url\n"+
+		"2025-01-01T10:01:00Z,Info,sid-1,1,https://splunk:8000/app/search/search?sid=sid-1\n"+
+		"2025-01-01T10:02:00Z,Severe,sid-2,4,https://splunk:8000/app/search/search?sid=sid-2\n", string(csvData))
+}
+
+func TestSplunkClient_SearchJobUrl(t *testing.T) {
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: "https://splunk:8089", WebBaseUrl: "https://splunk:8000/", AccessToken: "token"})
+	require.NoError(t, err)
+	require.Equal(t, "https://splunk:8000/app/search/search?sid=scheduler__admin__search__RMD5_at_1700000000_1", c.SearchJobUrl("scheduler__admin__search__RMD5_at_1700000000_1"))
+	require.Empty(t, c.SearchJobUrl(""))
+
+	c, err = NewSplunkClient(config.Instance{ApiBaseUrl: "https://splunk:8089", AccessToken: "token"})
+	require.NoError(t, err)
+	require.Empty(t, c.SearchJobUrl("sid-1"), "there is no link without the url of Splunk Web")
+}
+
+func TestAlertCheckAction_checkFiredAlerts_artifactsOnFailEarly(t *testing.T) {
+	now := time.Now()
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          now.Add(-2 * time.Minute),
+		End:            now.Add(1 * time.Minute),
+		ExpectedState:  alertNotFired,
+		StateCheckMode: stateCheckModeAllTheTime,
+		FailEarly:      true,
+	}
+
+	result, err := checkFiredAlerts(t.Context(), &state, MockSplunkClient{response: firedAlertsAt(now.Add(-1 * time.Minute).Unix())})
+
+	require.NoError(t, err)
+	require.NotNil(t, result.Error)
+	require.NotNil(t, result.Artifacts)
+	require.Len(t, state.FiredAlerts, 1)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_fetchesIncrementally(t *testing.T) {
+	now := time.Now()
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          now.Add(-2 * time.Minute),
+		End:            now.Add(1 * time.Minute),
+		StateCheckMode: stateCheckModeAtMostNTimes,
+		FireCount:      2,
+		FailEarly:      true,
+	}
+	first := Entry{Content: Content{TriggerTime: now.Add(-90 * time.Second).Unix(), Sid: "sid-1"}}
+	second := Entry{Content: Content{TriggerTime: now.Add(-30 * time.Second).Unix(), Sid: "sid-2"}}
+	sameSecond := Entry{Content: Content{TriggerTime: now.Add(-30 * time.Second).Unix(), Sid: "sid-3"}}
+
+	result, err := checkFiredAlerts(t.Context(), &state, MockSplunkClient{response: []Entry{first, second}})
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+	require.Equal(t, second.Content.TriggerTime, state.FiredAlertsCursor)
+
+	// the cursor second is fetched again, already known alerts are not counted twice
+	result, err = checkFiredAlerts(t.Context(), &state, MockSplunkClient{response: []Entry{second, sameSecond}})
+	require.NoError(t, err)
+	require.Equal(t, []FiredAlert{newFiredAlert(first), newFiredAlert(second), newFiredAlert(sameSecond)}, state.FiredAlerts)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Alert "Alert Name" should have been fired at most 2 times but was fired 3 times.`, result.Error.Title)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_reportsNewestFiredAlert(t *testing.T) {
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	state := AlertCheckState{
+		Name:           "Alert Name",
+		Start:          start,
+		End:            time.Now().Add(1 * time.Minute),
+		ExpectedState:  alertNotFired,
+		StateCheckMode: stateCheckModeAllTheTime,
+		FailEarly:      true,
+	}
+	// Splunk lists the newest fired alert first, the state keeps them oldest first
+	mockClient := MockSplunkClient{response: firedAlertsAt(start.Add(2*time.Minute).Unix(), start.Add(1*time.Minute).Unix())}
+
+	result, err := checkFiredAlerts(t.Context(), &state, mockClient)
+
+	require.NoError(t, err)
+	require.Equal(t, start.Add(1*time.Minute).Unix(), state.FiredAlerts[0].TriggerTime)
+	require.Equal(t, "2025-01-01T10:02:00Z", (*result.Metrics)[0].Metric[metricTriggerTime])
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Alert "Alert Name" should not have been fired but was at 2025-01-01T10:02:00Z.`, result.Error.Title)
+}
+
+func TestAlertCheckAction_Prepare_quietPeriodLongerThanStep(t *testing.T) {
+	action := NewAlertCheckAction(resolveTo[AlertCheckClient](MockSplunkClient{}))
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				attributeID:   {"id"},
+				attributeName: {"name"},
+				attributeUrl:  {"url"},
+			},
+		},
+		Config: map[string]any{
+			"duration":       60_000,
+			"expectedState":  alertFired,
+			"stateCheckMode": stateCheckModeFiredThenCleared,
+			"quietPeriod":    120_000,
+		},
+	})
+
+	require.EqualError(t, err, "quiet period of 2m0s must not be longer than the step duration of 1m0s")
+}
+
+func TestAlertCheckAction_Prepare_cursorStartsAtStep(t *testing.T) {
+	action := NewAlertCheckAction(resolveTo[AlertCheckClient](MockSplunkClient{}))
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				attributeID:   {"id"},
+				attributeName: {"name"},
+				attributeUrl:  {"url"},
+			},
+		},
+		Config: map[string]any{
+			"duration":           1000,
+			"expectedState":      alertFired,
+			"stateCheckMode":     stateCheckModeAtLeastOnce,
+			"checkNewAlertsOnly": false,
+		},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, state.Start.Unix(), state.FiredAlertsCursor)
+}
+
+func TestAlertCheckAction_checkFiredAlerts_keepsOnlyPreviousTriggerTime(t *testing.T) {
+	start := time.Now().Add(-1 * time.Minute)
+	state := AlertCheckState{
+		Name:              "Alert Name",
+		Start:             start,
+		End:               time.Now().Add(1 * time.Minute),
+		ExpectedState:     alertFired,
+		StateCheckMode:    stateCheckModeAtLeastOnce,
+		FiredAlertsCursor: start.Unix(),
+	}
+	mockClient := MockSplunkClient{response: firedAlertsAt(start.Add(-1*time.Hour).Unix(), start.Add(-2*time.Hour).Unix())}
+
+	result, err := checkFiredAlerts(t.Context(), &state, mockClient)
+
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+	require.True(t, state.StateCheckSuccess, "the alert fired before the step counts")
+	require.Equal(t, start.Add(-1*time.Hour).Unix(), state.PreviousTriggerTime)
+	require.Empty(t, state.FiredAlerts, "alerts fired before the step are not kept")
+	require.Equal(t, time.Unix(state.PreviousTriggerTime, 0).UTC().Format(time.RFC3339), (*result.Metrics)[0].Metric[metricTriggerTime])
+}
+
+func TestAlertCheckAction_checkFiredAlerts_countsAlertsOfStepOnly(t *testing.T) {
+	start := time.Now().Add(-1 * time.Minute)
+	state := AlertCheckState{
+		Name:              "Alert Name",
+		Start:             start,
+		End:               time.Now().Add(-1 * time.Second),
+		StateCheckMode:    stateCheckModeAtLeastNTimes,
+		FireCount:         2,
+		FiredAlertsCursor: start.Unix(),
+	}
+	mockClient := MockSplunkClient{response: firedAlertsAt(start.Add(10*time.Second).Unix(), start.Add(-1*time.Hour).Unix())}
+
+	result, err := checkFiredAlerts(t.Context(), &state, mockClient)
+
+	require.NoError(t, err)
+	require.False(t, state.PreviousTriggerTimeFetched)
+	require.Len(t, state.FiredAlerts, 1)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Alert "Alert Name" should have been fired at least 2 times but was fired 1 times.`, result.Error.Title)
+}
@@ -6,12 +6,18 @@ package extalert
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"github.com/go-resty/resty/v2"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/extension-splunk-platform/config"
+	"io"
+	"net/url"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -23,42 +29,466 @@ const (
 	attributeAuthor   = "splunk.alert.author"
 	attributeSeverity = "splunk.alert.severity"
 	attributeUrl      = "splunk.alert.url"
+	attributeInstance = "splunk.instance.name"
 
 	metricId          = "splunk.alert.metric.id"
 	metricLabel       = "splunk.alert.metric.label"
 	metricState       = "splunk.alert.metric.severity"
 	metricTooltip     = "splunk.alert.metric.tooltip"
 	metricTriggerTime = "splunk.alert.metric.triggerTime"
+
+	IndexTargetType = "com.steadybit.extension_splunk_platform.index"
+	indexIcon       = "data:image/svg+xml;base64,PHN2ZyB4bWxucz0iaHR0cDovL3d3dy53My5vcmcvMjAwMC9zdmciIHZpZXdCb3g9IjAgMCAyNCAyNCIgZmlsbD0ibm9uZSI+PHBhdGggZmlsbC1ydWxlPSJldmVub2RkIiBjbGlwLXJ1bGU9ImV2ZW5vZGQiIGQ9Ik0xMiAyQzcuNTggMiA0IDMuMzQgNCA1djE0YzAgMS42NiAzLjU4IDMgOCAzczgtMS4zNCA4LTNWNWMwLTEuNjYtMy41OC0zLTgtM1ptNiAzYzAgLjMtLjk1LjkyLTIuNzcgMS40LS45My4zNS0yLjAzLjYtMy4yMy42cy0yLjMtLjI1LTMuMjMtLjZDNi45NSA1LjkyIDYgNS4zIDYgNXMuOTUtLjkyIDIuNzctMS40QzkuNyAzLjI1IDEwLjggMyAxMiAzczIuMy4yNSAzLjIzLjZDMTcuMDUgNC4wOCAxOCA0LjcgMTggNVpNNiA3LjM1QzcuNDYgOC4wOCA5LjYgOC41IDEyIDguNXM0LjU0LS40MiA2LTEuMTVWMTJjMCAuMy0uOTUuOTItMi43NyAxLjQtLjkzLjM1LTIuMDMuNi0zLjIzLjZzLTIuMy0uMjUtMy4yMy0uNkM2Ljk1IDEyLjkyIDYgMTIuMyA2IDEyVjcuMzVabTAgN2MxLjQ2LjczIDMuNiAxLjE1IDYgMS4xNXM0LjU0LS40MiA2LTEuMTVWMTljMCAuMy0uOTUuOTItMi43NyAxLjQtLjkzLjM1LTIuMDMuNi0zLjIzLjZzLTIuMy0uMjUtMy4yMy0uNkM2Ljk1IDE5LjkyIDYgMTkuMyA2IDE5di00LjY1WiIgZmlsbD0iY3VycmVudENvbG9yIi8+PC9zdmc+"
+
+	attributeIndexName             = "splunk.index.name"
+	attributeIndexDatatype         = "splunk.index.datatype"
+	attributeIndexCurrentSizeMB    = "splunk.index.current-size-mb"
+	attributeIndexEventCount       = "splunk.index.event-count"
+	attributeIndexEarliestTime     = "splunk.index.earliest-time"
+	attributeIndexLatestTime       = "splunk.index.latest-time"
+	attributeIndexFrozenTimePeriod = "splunk.index.frozen-time-period-seconds"
+
+	SavedSearchTargetType = "com.steadybit.extension_splunk_platform.saved-search"
+
+	attributeSavedSearchID           = "splunk.saved-search.id"
+	attributeSavedSearchName         = "splunk.saved-search.name"
+	attributeSavedSearchApp          = "splunk.saved-search.app"
+	attributeSavedSearchOwner        = "splunk.saved-search.owner"
+	attributeSavedSearchSharing      = "splunk.saved-search.sharing"
+	attributeSavedSearchCronSchedule = "splunk.saved-search.cron-schedule"
+	attributeSavedSearchIsScheduled  = "splunk.saved-search.is-scheduled"
+	attributeSavedSearchDisabled     = "splunk.saved-search.disabled"
+	attributeSavedSearchAlertType    = "splunk.saved-search.alert-type"
+	attributeSavedSearchActions      = "splunk.saved-search.actions"
+	attributeSavedSearchDispatchUrl  = "splunk.saved-search.dispatch-url"
+
+	searchType = "com.steadybit.extension_splunk_platform.search"
+	searchIcon = "data:image/svg+xml;base64,PHN2ZyB4bWxucz0iaHR0cDovL3d3dy53My5vcmcvMjAwMC9zdmciIHZpZXdCb3g9IjAgMCAyNCAyNCIgZmlsbD0ibm9uZSI+PHBhdGggZmlsbC1ydWxlPSJldmVub2RkIiBjbGlwLXJ1bGU9ImV2ZW5vZGQiIGQ9Ik0xMCAzQzYuMTM0MDEgMyAzIDYuMTM0MDEgMyAxMEMzIDEzLjg2NiA2LjEzNDAxIDE3IDEwIDE3QzExLjU3MjMgMTcgMTMuMDIzNiAxNi40ODE2IDE0LjE5MjIgMTUuNjA2NEwxOS4yOTI5IDIwLjcwNzFDMTkuNjgzNCAyMS4wOTc2IDIwLjMxNjYgMjEuMDk3NiAyMC43MDcxIDIwLjcwNzFDMjEuMDk3NiAyMC4zMTY2IDIxLjA5NzYgMTkuNjgzNCAyMC43MDcxIDE5LjI5MjlMMTUuNjA2NCAxNC4xOTIyQzE2LjQ4MTYgMTMuMDIzNiAxNyAxMS41NzIzIDE3IDEwQzE3IDYuMTM0MDEgMTMuODY2IDMgMTAgM1pNNSAxMEM1IDcuMjM4NTggNy4yMzg1OCA1IDEwIDVDMTIuNzYxNCA1IDE1IDcuMjM4NTggMTUgMTBDMTUgMTIuNzYxNCAxMi43NjE0IDE1IDEwIDE1QzcuMjM4NTggMTUgNSAxMi43NjE0IDUgMTBaIiBmaWxsPSJjdXJyZW50Q29sb3IiLz48L3N2Zz4="
+
+	searchMetricId      = "splunk.search.metric.id"
+	searchMetricLabel   = "splunk.search.metric.label"
+	searchMetricState   = "splunk.search.metric.state"
+	searchMetricTooltip = "splunk.search.metric.tooltip"
+	searchMetricSeries  = "splunk.search.metric.series"
+
+	searchSeriesMetricName = "splunk_search_series"
+	freshnessMetricName    = "splunk_index_freshness"
+
+	// searchJobTTL is how long Splunk keeps the search jobs of the checks after they finished. The results are read
+	// right away, so there is no need to keep them for the default of ten minutes.
+	searchJobTTL = 2 * time.Minute
 )
 
 type SplunkClient struct {
 	client *resty.Client
+	// webBaseUrl is the url of Splunk Web, to link to search jobs. Empty if not configured.
+	webBaseUrl string
+	preflight  preflightCache
 }
 
-func NewSplunkClient() *SplunkClient {
+func NewSplunkClient(instance config.Instance) (*SplunkClient, error) {
+	tlsConfig, err := newTLSConfig(instance)
+	if err != nil {
+		return nil, err
+	}
+
 	client := resty.New()
-	if config.Config.InsecureSkipVerify {
-		client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true}) //NOSONAR explicit choice
+	client.SetTLSClientConfig(tlsConfig)
+	client.SetBaseURL(strings.TrimRight(instance.ApiBaseUrl, "/"))
+	if err := useProxy(client, instance); err != nil {
+		return nil, err
+	}
+	client.SetTransport(newThrottledTransport(client.GetClient().Transport, instance.Name, config.Config.RateLimit, config.Config.RateLimitBurst, config.Config.MaxConcurrentRequests))
+	useRetries(client, config.Config.MaxRetries, config.Config.RetryWaitTime, config.Config.RetryMaxWaitTime)
+	if err := useAuth(client, instance); err != nil {
+		return nil, err
 	}
-	client.SetBaseURL(strings.TrimRight(config.Config.ApiBaseUrl, "/"))
-	client.SetHeader("Authorization", "Bearer "+config.Config.AccessToken)
 	client.SetHeader("Content-Type", "application/json")
 	return &SplunkClient{
-		client: client,
+		client:     client,
+		webBaseUrl: strings.TrimRight(instance.WebBaseUrl, "/"),
+	}, nil
+}
+
+// newTLSConfig configures the client certificate for management ports requiring mutual TLS and the CA certificates
+// to trust instead of the system trust store.
+func newTLSConfig(instance config.Instance) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: instance.InsecureSkipVerify, //NOSONAR explicit choice
+	}
+
+	if instance.ClientCertificateFile != "" || instance.ClientKeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(instance.ClientCertificateFile, instance.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	if instance.CaCertificateFile != "" {
+		caCertificates, err := os.ReadFile(instance.CaCertificateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificates: %w", err)
+		}
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caCertificates) {
+			return nil, fmt.Errorf("no PEM encoded certificates found in %s", instance.CaCertificateFile)
+		}
+		tlsConfig.RootCAs = rootCAs
 	}
+	return tlsConfig, nil
 }
 
 func (c *SplunkClient) Alerts(ctx context.Context) ([]Entry, error) {
 	return c.query(ctx, "/services/saved/searches", map[string]string{
 		"search": "alert.track=1",
-	})
+	}, nil)
 }
 
 func (c *SplunkClient) FiredAlerts(ctx context.Context, alertUrl string) ([]Entry, error) {
-	return c.query(ctx, alertUrl, nil)
+	return c.query(ctx, alertUrl, nil, nil)
+}
+
+// SavedSearches returns all saved searches the user can access, including reports, untracked and disabled alerts.
+func (c *SplunkClient) SavedSearches(ctx context.Context) ([]Entry, error) {
+	return c.query(ctx, "/services/saved/searches", map[string]string{
+		"listDefaultActionArgs": "false",
+	}, nil)
+}
+
+// Indexes returns the event and metrics indexes the user can access.
+func (c *SplunkClient) Indexes(ctx context.Context) ([]Entry, error) {
+	return c.query(ctx, "/services/data/indexes", map[string]string{
+		"datatype": "all",
+	}, nil)
+}
+
+// FiredAlertsSince returns the alerts fired at or after since, newest first. Splunk sorts the fired alerts by trigger
+// time, so paging stops at the first page reaching older alerts instead of reading the whole history.
+func (c *SplunkClient) FiredAlertsSince(ctx context.Context, alertUrl string, since int64) ([]Entry, error) {
+	entries, err := c.query(ctx, alertUrl, map[string]string{
+		"sort_key":  "trigger_time",
+		"sort_dir":  "desc",
+		"sort_mode": "num",
+	}, func(page []Entry) bool {
+		return page[len(page)-1].Content.TriggerTime < since
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the last page may contain older alerts
+	return slices.DeleteFunc(entries, func(entry Entry) bool {
+		return entry.Content.TriggerTime < since
+	}), nil
+}
+
+// LatestFiredAlert returns the newest fired alert, nil if the alert never fired. Only a single fired alert is requested.
+func (c *SplunkClient) LatestFiredAlert(ctx context.Context, alertUrl string) (*Entry, error) {
+	var response Response
+	res, err := c.client.R().
+		SetContext(ctx).
+		SetResult(&response).
+		SetQueryParams(map[string]string{
+			"count":       "1",
+			"sort_key":    "trigger_time",
+			"sort_dir":    "desc",
+			"sort_mode":   "num",
+			"output_mode": "json",
+		}).
+		Get(alertUrl)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve %s from Splunk: %w", alertUrl, err)
+	}
+
+	if res.StatusCode() != 200 {
+		return nil, newSplunkError(res.StatusCode(), res.Body())
+	}
+
+	if len(response.Entries) == 0 {
+		return nil, nil
+	}
+	return &response.Entries[0], nil
+}
+
+// DispatchSearch creates a search job for the given SPL query covering [earliest, latest] and returns its sid.
+func (c *SplunkClient) DispatchSearch(ctx context.Context, query string, earliest, latest time.Time) (string, error) {
+	var response DispatchResponse
+	res, err := c.client.R().
+		SetContext(ctx).
+		SetResult(&response).
+		SetFormData(map[string]string{
+			"search":        normalizeQuery(query),
+			"earliest_time": strconv.FormatInt(earliest.Unix(), 10),
+			"latest_time":   strconv.FormatInt(latest.Unix(), 10),
+			"timeout":       strconv.Itoa(int(searchJobTTL.Seconds())),
+			"output_mode":   "json",
+		}).
+		Post("/services/search/jobs")
+
+	if err != nil {
+		return "", fmt.Errorf("failed to dispatch search in Splunk: %w", err)
+	}
+
+	if res.StatusCode() != 201 && res.StatusCode() != 200 {
+		return "", newSplunkError(res.StatusCode(), res.Body())
+	}
+
+	if response.Sid == "" {
+		return "", fmt.Errorf("search job response is missing the sid. full response: %v", res.String())
+	}
+	return response.Sid, nil
+}
+
+// CancelSearch cancels the search job and deletes its results. Jobs which already expired are ignored.
+func (c *SplunkClient) CancelSearch(ctx context.Context, sid string) error {
+	res, err := c.client.R().
+		SetContext(ctx).
+		SetFormData(map[string]string{
+			"action":      "cancel",
+			"output_mode": "json",
+		}).
+		Post("/services/search/jobs/" + url.PathEscape(sid) + "/control")
+
+	if err != nil {
+		return fmt.Errorf("failed to cancel search job in Splunk: %w", err)
+	}
+
+	if res.StatusCode() == 404 {
+		return nil
+	}
+	if res.StatusCode() != 200 {
+		return newSplunkError(res.StatusCode(), res.Body())
+	}
+	return nil
+}
+
+// SavedSearch returns the saved search at the given path.
+func (c *SplunkClient) SavedSearch(ctx context.Context, path string) (*Entry, error) {
+	var response Response
+	res, err := c.client.R().
+		SetContext(ctx).
+		SetResult(&response).
+		SetQueryParam("output_mode", "json").
+		Get(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve saved search from Splunk: %w", err)
+	}
+
+	if res.StatusCode() != 200 {
+		return nil, newSplunkError(res.StatusCode(), res.Body())
+	}
+
+	if len(response.Entries) == 0 {
+		return nil, fmt.Errorf("saved search %s not found", path)
+	}
+	return &response.Entries[0], nil
+}
+
+// SetSavedSearchDisabled disables or enables the saved search at the given path.
+func (c *SplunkClient) SetSavedSearchDisabled(ctx context.Context, path string, disabled bool) error {
+	value := "0"
+	if disabled {
+		value = "1"
+	}
+	res, err := c.client.R().
+		SetContext(ctx).
+		SetFormData(map[string]string{
+			"disabled":    value,
+			"output_mode": "json",
+		}).
+		Post(path)
+
+	if err != nil {
+		return fmt.Errorf("failed to update saved search in Splunk: %w", err)
+	}
+
+	if res.StatusCode() != 200 {
+		return newSplunkError(res.StatusCode(), res.Body())
+	}
+	return nil
+}
+
+// DispatchSavedSearch runs the saved search behind the dispatch url and returns the sid of its search job. Zero times
+// keep the time range of the saved search. Alert actions of the saved search are not triggered.
+func (c *SplunkClient) DispatchSavedSearch(ctx context.Context, dispatchUrl string, earliest, latest time.Time) (string, error) {
+	formData := map[string]string{
+		"trigger_actions": "0",
+		"dispatch.ttl":    strconv.Itoa(int(searchJobTTL.Seconds())),
+		"output_mode":     "json",
+	}
+	if !earliest.IsZero() {
+		formData["dispatch.earliest_time"] = strconv.FormatInt(earliest.Unix(), 10)
+	}
+	if !latest.IsZero() {
+		formData["dispatch.latest_time"] = strconv.FormatInt(latest.Unix(), 10)
+	}
+
+	var response DispatchResponse
+	res, err := c.client.R().
+		SetContext(ctx).
+		SetResult(&response).
+		SetFormData(formData).
+		Post(dispatchUrl)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to dispatch saved search in Splunk: %w", err)
+	}
+
+	if res.StatusCode() != 201 && res.StatusCode() != 200 {
+		return "", newSplunkError(res.StatusCode(), res.Body())
+	}
+
+	if response.Sid == "" {
+		return "", fmt.Errorf("saved search dispatch response is missing the sid. full response: %v", res.String())
+	}
+	return response.Sid, nil
+}
+
+func (c *SplunkClient) SearchJob(ctx context.Context, sid string) (*SearchJobContent, error) {
+	var response SearchJobResponse
+	res, err := c.client.R().
+		SetContext(ctx).
+		SetResult(&response).
+		SetQueryParam("output_mode", "json").
+		Get("/services/search/jobs/" + url.PathEscape(sid))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve search job from Splunk: %w", err)
+	}
+
+	if res.StatusCode() != 200 {
+		return nil, newSplunkError(res.StatusCode(), res.Body())
+	}
+
+	if len(response.Entries) == 0 {
+		return nil, fmt.Errorf("search job %s not found", sid)
+	}
+	return &response.Entries[0].Content, nil
+}
+
+// SearchJobUrl links to the search job in Splunk Web. It is empty if the url of Splunk Web is not configured.
+func (c *SplunkClient) SearchJobUrl(sid string) string {
+	return searchJobUrl(c.webBaseUrl, sid)
+}
+
+func searchJobUrl(webBaseUrl string, sid string) string {
+	if webBaseUrl == "" || sid == "" {
+		return ""
+	}
+	return webBaseUrl + "/app/search/search?sid=" + url.QueryEscape(sid)
+}
+
+// SearchResults returns the first rows of a finished search job. The number of rows is capped, as searches
+// returning raw events can be arbitrarily large.
+func (c *SplunkClient) SearchResults(ctx context.Context, sid string) ([]map[string]any, error) {
+	const maxResults = 1000
+	var response SearchResultsResponse
+	res, err := c.client.R().
+		SetContext(ctx).
+		SetResult(&response).
+		SetQueryParam("count", strconv.Itoa(maxResults)).
+		SetQueryParam("output_mode", "json").
+		Get("/services/search/jobs/" + url.PathEscape(sid) + "/results")
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve search results from Splunk: %w", err)
+	}
+
+	if res.StatusCode() != 200 {
+		return nil, newSplunkError(res.StatusCode(), res.Body())
+	}
+	return response.Results, nil
+}
+
+// ServerInfo returns the version of the Splunk instance. It requires authentication, so it also verifies the
+// credentials.
+func (c *SplunkClient) ServerInfo(ctx context.Context) (*ServerInfoContent, error) {
+	var response ServerInfoResponse
+	res, err := c.client.R().
+		SetContext(ctx).
+		SetResult(&response).
+		SetQueryParam("output_mode", "json").
+		Get("/services/server/info")
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve server info from Splunk: %w", err)
+	}
+
+	if res.StatusCode() != 200 {
+		return nil, newSplunkError(res.StatusCode(), res.Body())
+	}
+
+	if len(response.Entries) == 0 {
+		return nil, fmt.Errorf("server info response is empty. full response: %v", res.String())
+	}
+	return &response.Entries[0].Content, nil
+}
+
+// CurrentContext returns the user the extension is authenticated as, with its roles and capabilities.
+func (c *SplunkClient) CurrentContext(ctx context.Context) (*CurrentContextContent, error) {
+	var response CurrentContextResponse
+	res, err := c.client.R().
+		SetContext(ctx).
+		SetResult(&response).
+		SetQueryParam("output_mode", "json").
+		Get("/services/authentication/current-context")
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve current context from Splunk: %w", err)
+	}
+
+	if res.StatusCode() != 200 {
+		return nil, newSplunkError(res.StatusCode(), res.Body())
+	}
+
+	if len(response.Entries) == 0 {
+		return nil, fmt.Errorf("current context response is empty. full response: %v", res.String())
+	}
+	return &response.Entries[0].Content, nil
+}
+
+// ExportSearch opens a real-time search via the export endpoint. Results are streamed as newline delimited JSON
+// until the context is cancelled. The caller must close the returned body.
+func (c *SplunkClient) ExportSearch(ctx context.Context, query string) (io.ReadCloser, error) {
+	res, err := c.client.R().
+		SetContext(ctx).
+		SetDoNotParseResponse(true).
+		SetFormData(map[string]string{
+			"search":        normalizeQuery(query),
+			"search_mode":   "realtime",
+			"earliest_time": "rt",
+			"latest_time":   "rt",
+			"output_mode":   "json",
+		}).
+		Post("/services/search/jobs/export")
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open search stream in Splunk: %w", err)
+	}
+
+	if res.StatusCode() != 200 {
+		defer res.RawBody().Close()
+		body, _ := io.ReadAll(res.RawBody())
+		return nil, newSplunkError(res.StatusCode(), body)
+	}
+	return res.RawBody(), nil
+}
+
+// normalizeQuery prefixes the query with the search command, as the search jobs API requires queries to start
+// with a generating command.
+func normalizeQuery(query string) string {
+	query = strings.TrimSpace(query)
+	if strings.HasPrefix(query, "|") || strings.HasPrefix(query, "search ") {
+		return query
+	}
+	return "search " + query
 }
 
-func (c *SplunkClient) query(ctx context.Context, url string, params map[string]string) ([]Entry, error) {
+// query reads all pages of a listing. If lastPage is given, paging stops early once it reports a non-empty page to be
+// the last one needed.
+func (c *SplunkClient) query(ctx context.Context, url string, params map[string]string, lastPage func(page []Entry) bool) ([]Entry, error) {
 	const pageSize = 30
 	var entries []Entry
 
@@ -80,11 +510,11 @@ func (c *SplunkClient) query(ctx context.Context, url string, params map[string]
 		res, err := request.Get(url)
 
 		if err != nil {
-			return nil, fmt.Errorf("failed to retrieve alerts from Splunk: %w", err)
+			return nil, fmt.Errorf("failed to retrieve %s from Splunk: %w", url, err)
 		}
 
 		if res.StatusCode() != 200 {
-			return nil, fmt.Errorf("unexpected status code %d. full response: %v", res.StatusCode(), res.String())
+			return nil, newSplunkError(res.StatusCode(), res.Body())
 		}
 
 		log.Trace().Msgf("Splunk response (offset: %d): %v", len(entries), response)
@@ -97,6 +527,9 @@ func (c *SplunkClient) query(ctx context.Context, url string, params map[string]
 		if len(response.Entries) < pageSize {
 			break
 		}
+		if lastPage != nil && lastPage(response.Entries) {
+			break
+		}
 	}
 	return entries, nil
 }
@@ -3,17 +3,95 @@
 
 package extalert
 
-import "context"
+import (
+	"cmp"
+	"context"
+	"github.com/steadybit/extension-splunk-platform/config"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
 
 type MockSplunkClient struct {
 	response []Entry
-	err      error
+	// firedAlerts overrides the response of FiredAlertsSince per alert url, if set.
+	firedAlerts map[string][]Entry
+	// results are the search results per sid.
+	results      map[string][]map[string]any
+	err          error
+	preflightErr error
+	// capabilities records the capabilities checked by the preflight checks, if set.
+	capabilities *[]string
+	webBaseUrl   string
+}
+
+// resolveTo resolves every instance to the given client.
+func resolveTo[T any](client T) ClientResolver[T] {
+	return func(string) (T, error) {
+		return client, nil
+	}
+}
+
+func (c MockSplunkClient) Preflight(_ context.Context, capabilities ...string) error {
+	if c.capabilities != nil {
+		*c.capabilities = append(*c.capabilities, capabilities...)
+	}
+	return c.preflightErr
 }
 
 func (c MockSplunkClient) Alerts(_ context.Context) ([]Entry, error) {
 	return c.response, c.err
 }
 
-func (c MockSplunkClient) FiredAlerts(ctx context.Context, alertUrl string) ([]Entry, error) {
+func (c MockSplunkClient) SavedSearches(_ context.Context) ([]Entry, error) {
 	return c.response, c.err
 }
+
+func (c MockSplunkClient) Indexes(_ context.Context) ([]Entry, error) {
+	return c.response, c.err
+}
+
+func (c MockSplunkClient) FiredAlertsSince(_ context.Context, alertUrl string, since int64) ([]Entry, error) {
+	firedAlerts := c.response
+	if c.firedAlerts != nil {
+		firedAlerts = c.firedAlerts[alertUrl]
+	}
+	var entries []Entry
+	for _, entry := range firedAlerts {
+		if entry.Content.TriggerTime >= since {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, c.err
+}
+
+func (c MockSplunkClient) LatestFiredAlert(ctx context.Context, alertUrl string) (*Entry, error) {
+	firedAlerts, err := c.FiredAlertsSince(ctx, alertUrl, 0)
+	if err != nil || len(firedAlerts) == 0 {
+		return nil, err
+	}
+	latest := slices.MaxFunc(firedAlerts, func(a, b Entry) int {
+		return cmp.Compare(a.Content.TriggerTime, b.Content.TriggerTime)
+	})
+	return &latest, nil
+}
+
+func (c MockSplunkClient) SearchResults(_ context.Context, sid string) ([]map[string]any, error) {
+	return c.results[sid], c.err
+}
+
+func (c MockSplunkClient) SearchJobUrl(sid string) string {
+	return searchJobUrl(c.webBaseUrl, sid)
+}
+
+func TestNewSplunkClient_InvalidCertificateFiles(t *testing.T) {
+	_, err := NewSplunkClient(config.Instance{ClientCertificateFile: "/does/not/exist.crt", ClientKeyFile: "/does/not/exist.key"})
+	require.ErrorContains(t, err, "failed to load client certificate")
+
+	emptyFile := filepath.Join(t.TempDir(), "empty.crt")
+	require.NoError(t, os.WriteFile(emptyFile, []byte("no certificate"), 0o600))
+	_, err = NewSplunkClient(config.Instance{CaCertificateFile: emptyFile})
+	require.ErrorContains(t, err, "no PEM encoded certificates found")
+}
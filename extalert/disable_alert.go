@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-kit/extbuild"
+	"net/url"
+	"sync"
+)
+
+type AlertDisableClient interface {
+	PreflightClient
+	SavedSearch(ctx context.Context, path string) (*Entry, error)
+	SetSavedSearchDisabled(ctx context.Context, path string, disabled bool) error
+}
+
+type DisableAlertAction struct {
+	Clients ClientResolver[AlertDisableClient]
+}
+
+var (
+	_ action_kit_sdk.Action[DisableAlertState]         = (*DisableAlertAction)(nil)
+	_ action_kit_sdk.ActionWithStop[DisableAlertState] = (*DisableAlertAction)(nil)
+)
+
+// DisableAlertState records the state of the alert before the attack, so Stop restores it even if the extension was
+// restarted in between.
+type DisableAlertState struct {
+	ExecutionId uuid.UUID
+	Instance    string
+	Name        string
+	// Path is the REST path of the saved search behind the alert.
+	Path string
+	// WasDisabled is whether the alert was already disabled before the attack, in which case it is left untouched.
+	WasDisabled bool
+}
+
+type disabledAlertKey struct {
+	instance string
+	path     string
+}
+
+// disabledAlerts holds the execution id of the attack disabling an alert. Overlapping attacks on the same alert are
+// refused, as the second one would record the alert as disabled and the first one would re-enable it too early.
+var disabledAlerts = sync.Map{}
+
+func NewDisableAlertAction(clients ClientResolver[AlertDisableClient]) action_kit_sdk.Action[DisableAlertState] {
+	return &DisableAlertAction{
+		Clients: clients,
+	}
+}
+
+func (a *DisableAlertAction) NewEmptyState() DisableAlertState {
+	return DisableAlertState{}
+}
+
+func (a *DisableAlertAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.disable", TargetType),
+		Label:       "Disable Alert",
+		Description: "Disable the saved search of an alert for the duration of the step, to verify that the outage is detected otherwise.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        new(targetIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType:          TargetType,
+			QuantityRestriction: new(action_kit_api.QuantityRestrictionExactlyOne),
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "Alert name",
+					Description: new("Find alert by name"),
+					Query:       attributeName + "=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Splunk"),
+		Category:    new("Monitoring"),
+		Kind:        action_kit_api.Attack,
+		TimeControl: action_kit_api.TimeControlExternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("60s"),
+				Required:     new(true),
+			},
+		},
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *DisableAlertAction) Prepare(ctx context.Context, state *DisableAlertState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	alertId := request.Target.Attributes[attributeID]
+	if len(alertId) == 0 {
+		return nil, fmt.Errorf("target is missing the id attribute")
+	}
+	alertName := request.Target.Attributes[attributeName]
+	if len(alertName) == 0 {
+		return nil, fmt.Errorf("target is missing the name attribute")
+	}
+	if instance := request.Target.Attributes[attributeInstance]; len(instance) > 0 {
+		state.Instance = instance[0]
+	}
+	state.ExecutionId = request.ExecutionId
+	state.Name = alertName[0]
+	state.Path = savedSearchPath(alertId[0], alertName[0])
+
+	key := disabledAlertKey{instance: state.Instance, path: state.Path}
+	if owner, loaded := disabledAlerts.LoadOrStore(key, state.ExecutionId); loaded && owner != state.ExecutionId {
+		state.Path = ""
+		return nil, fmt.Errorf("alert %q is already disabled by another running attack", state.Name)
+	}
+
+	wasDisabled, err := a.wasDisabled(ctx, state)
+	if err != nil {
+		disabledAlerts.CompareAndDelete(key, state.ExecutionId)
+		return nil, err
+	}
+	state.WasDisabled = wasDisabled
+
+	log.Trace().Any("state", state).Msg("disable alert action state")
+
+	return nil, nil
+}
+
+func (a *DisableAlertAction) Start(ctx context.Context, state *DisableAlertState) (*action_kit_api.StartResult, error) {
+	if state.WasDisabled {
+		return &action_kit_api.StartResult{
+			Messages: new([]action_kit_api.Message{
+				{Level: new(action_kit_api.Warn), Message: fmt.Sprintf("Alert %q is already disabled.", state.Name)},
+			}),
+		}, nil
+	}
+
+	client, err := a.Clients(state.Instance)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.SetSavedSearchDisabled(ctx, state.Path, true); err != nil {
+		return nil, toExtensionError(err)
+	}
+	log.Info().Str("instance", state.Instance).Str("alert", state.Name).Msg("Disabled alert")
+	return &action_kit_api.StartResult{
+		Messages: new([]action_kit_api.Message{
+			{Level: new(action_kit_api.Info), Message: fmt.Sprintf("Disabled alert %q.", state.Name)},
+		}),
+	}, nil
+}
+
+// wasDisabled reads the original state of the alert. It is read before the attack starts, so a repeated start can't
+// mistake the disabled alert for its original state.
+func (a *DisableAlertAction) wasDisabled(ctx context.Context, state *DisableAlertState) (bool, error) {
+	if err := preflight(ctx, a.Clients, state.Instance, capabilityListSavedSearches, capabilityScheduleSearch); err != nil {
+		return false, err
+	}
+	client, err := a.Clients(state.Instance)
+	if err != nil {
+		return false, err
+	}
+	savedSearch, err := client.SavedSearch(ctx, state.Path)
+	if err != nil {
+		return false, toExtensionError(err)
+	}
+	return bool(savedSearch.Content.Disabled), nil
+}
+
+// Stop re-enables the alert unless it was disabled before the attack. It is safe to call repeatedly and also if the
+// attack never started.
+func (a *DisableAlertAction) Stop(ctx context.Context, state *DisableAlertState) (*action_kit_api.StopResult, error) {
+	if state.Path == "" {
+		return nil, nil
+	}
+	key := disabledAlertKey{instance: state.Instance, path: state.Path}
+	if state.WasDisabled {
+		disabledAlerts.CompareAndDelete(key, state.ExecutionId)
+		return nil, nil
+	}
+
+	client, err := a.Clients(state.Instance)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.SetSavedSearchDisabled(ctx, state.Path, false); err != nil {
+		return nil, toExtensionError(err)
+	}
+	disabledAlerts.CompareAndDelete(key, state.ExecutionId)
+	log.Info().Str("instance", state.Instance).Str("alert", state.Name).Msg("Re-enabled alert")
+	return &action_kit_api.StopResult{
+		Messages: new([]action_kit_api.Message{
+			{Level: new(action_kit_api.Info), Message: fmt.Sprintf("Re-enabled alert %q.", state.Name)},
+		}),
+	}, nil
+}
+
+// savedSearchPath returns the REST path of a saved search from its id, which Splunk reports as absolute url. It falls
+// back to the global namespace if the id is no url.
+func savedSearchPath(id string, name string) string {
+	if parsed, err := url.Parse(id); err == nil && parsed.Path != "" && parsed.Host != "" {
+		return parsed.EscapedPath()
+	}
+	return "/services/saved/searches/" + url.PathEscape(name)
+}
@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-splunk-platform/config"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// MockAlertDisableClient keeps the disabled state of a single saved search.
+type MockAlertDisableClient struct {
+	disabled bool
+	// updates records the disabled values set, in order.
+	updates []bool
+	// capabilities records the capabilities checked by the preflight checks.
+	capabilities []string
+	err          error
+}
+
+func (c *MockAlertDisableClient) Preflight(_ context.Context, capabilities ...string) error {
+	c.capabilities = append(c.capabilities, capabilities...)
+	return nil
+}
+
+func (c *MockAlertDisableClient) SavedSearch(_ context.Context, _ string) (*Entry, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &Entry{Content: Content{Disabled: Flag(c.disabled)}}, nil
+}
+
+func (c *MockAlertDisableClient) SetSavedSearchDisabled(_ context.Context, _ string, disabled bool) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.disabled = disabled
+	c.updates = append(c.updates, disabled)
+	return nil
+}
+
+func prepareDisableAlert(t *testing.T, action action_kit_sdk.Action[DisableAlertState]) DisableAlertState {
+	state := action.NewEmptyState()
+	_, err := action.Prepare(t.Context(), &state, disableAlertRequest())
+	require.NoError(t, err)
+	return state
+}
+
+func disableAlertRequest() action_kit_api.PrepareActionRequestBody {
+	return action_kit_api.PrepareActionRequestBody{
+		ExecutionId: uuid.New(),
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				attributeID:       {"https://localhost:8089/servicesNS/nobody/search/saved/searches/Key%20Alert"},
+				attributeName:     {"Key Alert"},
+				attributeInstance: {"production"},
+			},
+		},
+		Config: map[string]any{"duration": 60000},
+	}
+}
+
+func TestDisableAlertAction_DisablesAndRestores(t *testing.T) {
+	client := &MockAlertDisableClient{}
+	action := NewDisableAlertAction(resolveTo[AlertDisableClient](client))
+
+	state := prepareDisableAlert(t, action)
+	require.Equal(t, []string{capabilityListSavedSearches, capabilityScheduleSearch}, client.capabilities)
+	require.Equal(t, "production", state.Instance)
+	require.Equal(t, "/servicesNS/nobody/search/saved/searches/Key%20Alert", state.Path)
+	require.False(t, state.WasDisabled)
+
+	_, err := action.Start(t.Context(), &state)
+	require.NoError(t, err)
+	require.True(t, client.disabled)
+
+	_, err = action.(action_kit_sdk.ActionWithStop[DisableAlertState]).Stop(t.Context(), &state)
+	require.NoError(t, err)
+	require.False(t, client.disabled)
+	require.Equal(t, []bool{true, false}, client.updates)
+}
+
+func TestDisableAlertAction_StopIsIdempotent(t *testing.T) {
+	client := &MockAlertDisableClient{}
+	action := NewDisableAlertAction(resolveTo[AlertDisableClient](client))
+	state := prepareDisableAlert(t, action)
+
+	_, err := action.Start(t.Context(), &state)
+	require.NoError(t, err)
+	_, err = action.Start(t.Context(), &state)
+	require.NoError(t, err)
+
+	// the state survives a restart of the extension, as it is passed in by the agent
+	restored := state
+	stop := NewDisableAlertAction(resolveTo[AlertDisableClient](client)).(action_kit_sdk.ActionWithStop[DisableAlertState])
+	_, err = stop.Stop(t.Context(), &restored)
+	require.NoError(t, err)
+	_, err = stop.Stop(t.Context(), &restored)
+	require.NoError(t, err)
+
+	require.False(t, client.disabled)
+}
+
+func TestDisableAlertAction_KeepsAlreadyDisabledAlert(t *testing.T) {
+	client := &MockAlertDisableClient{disabled: true}
+	action := NewDisableAlertAction(resolveTo[AlertDisableClient](client))
+
+	state := prepareDisableAlert(t, action)
+	require.True(t, state.WasDisabled)
+
+	result, err := action.Start(t.Context(), &state)
+	require.NoError(t, err)
+	require.Len(t, *result.Messages, 1)
+	_, err = action.(action_kit_sdk.ActionWithStop[DisableAlertState]).Stop(t.Context(), &state)
+	require.NoError(t, err)
+
+	require.True(t, client.disabled)
+	require.Empty(t, client.updates)
+}
+
+func TestDisableAlertAction_StopWithoutPrepare(t *testing.T) {
+	client := &MockAlertDisableClient{}
+	action := NewDisableAlertAction(resolveTo[AlertDisableClient](client))
+	state := action.NewEmptyState()
+
+	_, err := action.(action_kit_sdk.ActionWithStop[DisableAlertState]).Stop(t.Context(), &state)
+
+	require.NoError(t, err)
+	require.Empty(t, client.updates)
+}
+
+func TestDisableAlertAction_RefusesOverlappingAttacks(t *testing.T) {
+	client := &MockAlertDisableClient{}
+	action := NewDisableAlertAction(resolveTo[AlertDisableClient](client))
+
+	first := prepareDisableAlert(t, action)
+	_, err := action.Start(t.Context(), &first)
+	require.NoError(t, err)
+
+	second := action.NewEmptyState()
+	_, err = action.Prepare(t.Context(), &second, disableAlertRequest())
+	require.EqualError(t, err, `alert "Key Alert" is already disabled by another running attack`)
+	_, err = action.(action_kit_sdk.ActionWithStop[DisableAlertState]).Stop(t.Context(), &second)
+	require.NoError(t, err)
+	require.True(t, client.disabled, "the refused attack must not re-enable the alert")
+
+	_, err = action.(action_kit_sdk.ActionWithStop[DisableAlertState]).Stop(t.Context(), &first)
+	require.NoError(t, err)
+	require.False(t, client.disabled)
+
+	third := prepareDisableAlert(t, action)
+	require.False(t, third.WasDisabled, "the alert can be attacked again once the first attack stopped")
+	_, err = action.(action_kit_sdk.ActionWithStop[DisableAlertState]).Stop(t.Context(), &third)
+	require.NoError(t, err)
+}
+
+func TestSavedSearchPath(t *testing.T) {
+	require.Equal(t, "/servicesNS/nobody/search/saved/searches/Key%20Alert", savedSearchPath("https://localhost:8089/servicesNS/nobody/search/saved/searches/Key%20Alert", "Key Alert"))
+	require.Equal(t, "/services/saved/searches/Key%20Alert", savedSearchPath("alert1", "Key Alert"))
+}
+
+func TestSplunkClient_SetSavedSearchDisabled(t *testing.T) {
+	var disabled string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/servicesNS/nobody/search/saved/searches/Key Alert", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			require.NoError(t, r.ParseForm())
+			disabled = r.PostForm.Get("disabled")
+		}
+		_, _ = w.Write([]byte(`{"entry":[{"name":"Key Alert","content":{"disabled":` + map[bool]string{true: "true", false: "false"}[disabled == "1"] + `}}]}`))
+	}))
+	defer srv.Close()
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AccessToken: "token"})
+	require.NoError(t, err)
+	path := "/servicesNS/nobody/search/saved/searches/Key%20Alert"
+
+	require.NoError(t, c.SetSavedSearchDisabled(t.Context(), path, true))
+	require.Equal(t, "1", disabled)
+	savedSearch, err := c.SavedSearch(t.Context(), path)
+	require.NoError(t, err)
+	require.True(t, bool(savedSearch.Content.Disabled))
+
+	require.NoError(t, c.SetSavedSearchDisabled(t.Context(), path, false))
+	require.Equal(t, "0", disabled)
+}
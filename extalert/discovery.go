@@ -5,12 +5,15 @@ package extalert
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
 	"github.com/steadybit/extension-kit/extbuild"
 	"github.com/steadybit/extension-splunk-platform/config"
+	"sync"
 	"time"
 )
 
@@ -19,7 +22,22 @@ type AlertClient interface {
 }
 
 type alertDiscovery struct {
-	Client AlertClient
+	Instances []string
+	Clients   ClientResolver[AlertClient]
+
+	targets instanceTargets
+}
+
+// instanceTargets discovers the targets of all instances. An unreachable instance doesn't hide the targets of the
+// others, discovery only fails if no instance could be queried. While an instance is temporarily unavailable, its
+// previously discovered targets are kept, so running experiments don't lose their targets.
+type instanceTargets struct {
+	// kind names the discovered targets in logs, e.g. "alerts".
+	kind string
+
+	mu sync.Mutex
+	// lastTargets are the targets last discovered per instance.
+	lastTargets map[string][]discovery_kit_api.Target
 }
 
 var (
@@ -27,17 +45,19 @@ var (
 	_ discovery_kit_sdk.AttributeDescriber = (*alertDiscovery)(nil)
 )
 
-func NewAlertDiscovery(client AlertClient) discovery_kit_sdk.TargetDiscovery {
-	discovery := newAlertDiscovery(client)
+func NewAlertDiscovery(instances []string, clients ClientResolver[AlertClient]) discovery_kit_sdk.TargetDiscovery {
+	discovery := newAlertDiscovery(instances, clients)
 	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
 		discovery_kit_sdk.WithRefreshTargetsNow(),
 		discovery_kit_sdk.WithRefreshTargetsInterval(context.Background(), 1*time.Minute),
 	)
 }
 
-func newAlertDiscovery(client AlertClient) *alertDiscovery {
+func newAlertDiscovery(instances []string, clients ClientResolver[AlertClient]) *alertDiscovery {
 	discovery := &alertDiscovery{
-		Client: client,
+		Instances: instances,
+		Clients:   clients,
+		targets:   newInstanceTargets("alerts"),
 	}
 	return discovery
 }
@@ -109,6 +129,13 @@ func (d *alertDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDescr
 				Other: "Fired Alert Urls",
 			},
 		},
+		{
+			Attribute: attributeInstance,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Splunk Instance",
+				Other: "Splunk Instances",
+			},
+		},
 	}
 }
 
@@ -117,10 +144,21 @@ func (d *alertDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_a
 }
 
 func (d *alertDiscovery) getAllAlertTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
-	alerts, err := d.Client.Alerts(ctx)
+	result, err := d.targets.discover(ctx, d.Instances, d.getAlertTargets)
+	if err != nil {
+		return result, err
+	}
+	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesAlert), nil
+}
+
+func (d *alertDiscovery) getAlertTargets(ctx context.Context, instance string) ([]discovery_kit_api.Target, error) {
+	client, err := d.Clients(instance)
 	if err != nil {
-		log.Warn().Err(err).Msg("Failed to retrieve alerts")
-		return make([]discovery_kit_api.Target, 0), err
+		return nil, err
+	}
+	alerts, err := client.Alerts(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	result := make([]discovery_kit_api.Target, 0, len(alerts))
@@ -135,7 +173,48 @@ func (d *alertDiscovery) getAllAlertTargets(ctx context.Context) ([]discovery_ki
 				attributeAuthor:   {alert.Author},
 				attributeSeverity: {alert.Content.Severity.String()},
 				attributeUrl:      {alert.Links.Alerts},
+				attributeInstance: {instance},
 			}})
 	}
-	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesAlert), nil
+	return result, nil
+}
+
+func newInstanceTargets(kind string) instanceTargets {
+	return instanceTargets{
+		kind:        kind,
+		lastTargets: make(map[string][]discovery_kit_api.Target),
+	}
+}
+
+func (t *instanceTargets) discover(ctx context.Context, instances []string, discover func(ctx context.Context, instance string) ([]discovery_kit_api.Target, error)) ([]discovery_kit_api.Target, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]discovery_kit_api.Target, 0)
+	var errs []error
+	for _, instance := range instances {
+		targets, err := discover(ctx, instance)
+		if err != nil {
+			if staleTargets, ok := t.lastTargets[instance]; ok && !isAccessLost(err) {
+				log.Warn().Err(err).Str("instance", instance).Int("count", len(staleTargets)).Msgf("Failed to retrieve %s, keeping the previously discovered ones", t.kind)
+				result = append(result, staleTargets...)
+				continue
+			}
+			delete(t.lastTargets, instance)
+			log.Warn().Err(err).Str("instance", instance).Msgf("Failed to retrieve %s", t.kind)
+			errs = append(errs, fmt.Errorf("instance %q: %w", instance, err))
+			continue
+		}
+		t.lastTargets[instance] = targets
+		result = append(result, targets...)
+	}
+	if len(errs) > 0 && len(errs) == len(instances) {
+		return make([]discovery_kit_api.Target, 0), errors.Join(errs...)
+	}
+	return result, nil
+}
+
+// isAccessLost tells whether the targets of an instance are no longer accessible, rather than temporarily unavailable.
+func isAccessLost(err error) bool {
+	return errors.Is(err, ErrAuthentication) || errors.Is(err, ErrPermissionDenied) || errors.Is(err, ErrNotFound)
 }
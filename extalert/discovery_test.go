@@ -8,13 +8,14 @@ import (
 	"fmt"
 	"github.com/steadybit/extension-splunk-platform/config"
 	"github.com/stretchr/testify/require"
+	"net/http"
 	"testing"
 )
 
 func TestAlertDiscovery_DiscoverTargets_noResponse(t *testing.T) {
-	discovery := newAlertDiscovery(MockSplunkClient{
+	discovery := newAlertDiscovery([]string{"default"}, resolveTo[AlertClient](MockSplunkClient{
 		response: []Entry{},
-	})
+	}))
 
 	targets, err := discovery.getAllAlertTargets(context.Background())
 
@@ -23,12 +24,12 @@ func TestAlertDiscovery_DiscoverTargets_noResponse(t *testing.T) {
 }
 
 func TestAlertDiscovery_DiscoverTargets_multipleAlerts(t *testing.T) {
-	discovery := newAlertDiscovery(MockSplunkClient{
+	discovery := newAlertDiscovery([]string{"default"}, resolveTo[AlertClient](MockSplunkClient{
 		response: []Entry{
 			{Id: "alert1", Name: "Alert One", Author: "Author1", Content: Content{Severity: SeverityFatal}},
 			{Id: "alert2", Name: "Alert Two", Author: "Author2", Content: Content{Severity: SeverityDebug}},
 		},
-	})
+	}))
 
 	targets, err := discovery.getAllAlertTargets(context.Background())
 
@@ -47,11 +48,11 @@ func TestAlertDiscovery_DiscoverTargets_multipleAlerts(t *testing.T) {
 }
 
 func TestAlertDiscovery_DiscoverTargets_invalidSeverity(t *testing.T) {
-	discovery := newAlertDiscovery(MockSplunkClient{
+	discovery := newAlertDiscovery([]string{"default"}, resolveTo[AlertClient](MockSplunkClient{
 		response: []Entry{
 			{Id: "alert1", Name: "Alert One", Author: "Author1", Content: Content{Severity: 99}},
 		},
-	})
+	}))
 
 	targets, err := discovery.getAllAlertTargets(context.Background())
 
@@ -66,11 +67,11 @@ func TestAlertDiscovery_DiscoverTargets_excludedAttributes(t *testing.T) {
 		config.Config.DiscoveryAttributesExcludesAlert = []string{}
 	}()
 
-	discovery := newAlertDiscovery(MockSplunkClient{
+	discovery := newAlertDiscovery([]string{"default"}, resolveTo[AlertClient](MockSplunkClient{
 		response: []Entry{
 			{Id: "alert1", Name: "Alert One", Author: "Author1", Content: Content{Severity: SeverityInfo}},
 		},
-	})
+	}))
 
 	targets, err := discovery.getAllAlertTargets(context.Background())
 
@@ -80,12 +81,55 @@ func TestAlertDiscovery_DiscoverTargets_excludedAttributes(t *testing.T) {
 }
 
 func TestAlertDiscovery_DiscoverTargets_errorResponse(t *testing.T) {
-	discovery := newAlertDiscovery(MockSplunkClient{
+	discovery := newAlertDiscovery([]string{"default"}, resolveTo[AlertClient](MockSplunkClient{
 		err: fmt.Errorf("some error"),
-	})
+	}))
 
 	targets, err := discovery.getAllAlertTargets(context.Background())
 
 	require.Empty(t, targets)
 	require.Error(t, err)
 }
+
+func TestAlertDiscovery_DiscoverTargets_multipleInstances(t *testing.T) {
+	clients := map[string]AlertClient{
+		"production":  MockSplunkClient{response: []Entry{{Id: "alert1", Name: "Alert One"}}},
+		"staging":     MockSplunkClient{response: []Entry{{Id: "alert2", Name: "Alert Two"}}},
+		"unreachable": MockSplunkClient{err: fmt.Errorf("some error")},
+	}
+	discovery := newAlertDiscovery([]string{"production", "unreachable", "staging"}, func(instance string) (AlertClient, error) {
+		return clients[instance], nil
+	})
+
+	targets, err := discovery.getAllAlertTargets(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	require.Equal(t, "alert1", targets[0].Id)
+	require.Equal(t, []string{"production"}, targets[0].Attributes[attributeInstance])
+	require.Equal(t, "alert2", targets[1].Id)
+	require.Equal(t, []string{"staging"}, targets[1].Attributes[attributeInstance])
+}
+
+func TestAlertDiscovery_DiscoverTargets_keepsStaleTargets(t *testing.T) {
+	var client AlertClient = MockSplunkClient{response: []Entry{{Id: "alert1", Name: "Alert One"}}}
+	discovery := newAlertDiscovery([]string{"default"}, func(string) (AlertClient, error) {
+		return client, nil
+	})
+	targets, err := discovery.getAllAlertTargets(context.Background())
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+
+	// temporarily unavailable
+	client = MockSplunkClient{err: newSplunkError(http.StatusServiceUnavailable, nil)}
+	targets, err = discovery.getAllAlertTargets(context.Background())
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	require.Equal(t, "alert1", targets[0].Id)
+
+	// no longer accessible
+	client = MockSplunkClient{err: newSplunkError(http.StatusForbidden, nil)}
+	targets, err = discovery.getAllAlertTargets(context.Background())
+	require.ErrorIs(t, err, ErrPermissionDenied)
+	require.Empty(t, targets)
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/steadybit/extension-kit"
+	"net/http"
+	"strings"
+)
+
+// Kinds of Splunk errors, to be checked with errors.Is.
+var (
+	ErrAuthentication      = errors.New("authentication failed")
+	ErrPermissionDenied    = errors.New("permission denied")
+	ErrNotFound            = errors.New("not found")
+	ErrSearchQuotaExceeded = errors.New("search quota exceeded")
+)
+
+// SplunkError is a failed Splunk REST request, with the messages Splunk explained the failure with.
+type SplunkError struct {
+	StatusCode int
+	Messages   []Message
+	// Body is the raw response, if it didn't contain messages.
+	Body string
+	kind error
+}
+
+func newSplunkError(statusCode int, body []byte) *SplunkError {
+	splunkErr := &SplunkError{StatusCode: statusCode}
+	var response ErrorResponse
+	if err := json.Unmarshal(body, &response); err == nil && len(response.Messages) > 0 {
+		splunkErr.Messages = response.Messages
+	} else {
+		splunkErr.Body = string(body)
+	}
+	splunkErr.kind = splunkErr.classify()
+	return splunkErr
+}
+
+func (e *SplunkError) classify() error {
+	for _, message := range e.Messages {
+		text := strings.ToLower(message.Text)
+		if strings.Contains(text, "quota") || strings.Contains(text, "maximum number of concurrent") {
+			return ErrSearchQuotaExceeded
+		}
+	}
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrAuthentication
+	case http.StatusForbidden:
+		return ErrPermissionDenied
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		return nil
+	}
+}
+
+func (e *SplunkError) Error() string {
+	if len(e.Messages) == 0 {
+		return fmt.Sprintf("unexpected status code %d. full response: %v", e.StatusCode, e.Body)
+	}
+	texts := make([]string, 0, len(e.Messages))
+	for _, message := range e.Messages {
+		texts = append(texts, message.Type+": "+message.Text)
+	}
+	return fmt.Sprintf("unexpected status code %d. %s", e.StatusCode, strings.Join(texts, "; "))
+}
+
+func (e *SplunkError) Unwrap() error {
+	return e.kind
+}
+
+// Title summarizes the error for users.
+func (e *SplunkError) Title() string {
+	switch e.kind {
+	case ErrAuthentication:
+		return "Splunk rejected the credentials"
+	case ErrPermissionDenied:
+		return "Permission denied by Splunk"
+	case ErrNotFound:
+		return "Not found in Splunk"
+	case ErrSearchQuotaExceeded:
+		return "Splunk search quota exceeded"
+	default:
+		return fmt.Sprintf("Splunk request failed with status code %d", e.StatusCode)
+	}
+}
+
+// toExtensionError titles Splunk errors, so they are shown meaningfully instead of as a generic action failure.
+func toExtensionError(err error) error {
+	var title string
+	var splunkErr *SplunkError
+	switch {
+	case errors.Is(err, ErrUnsupportedVersion):
+		title = "Splunk version not supported"
+	case errors.Is(err, ErrMissingCapabilities):
+		title = "Missing Splunk capabilities"
+	case errors.As(err, &splunkErr):
+		title = splunkErr.Title()
+	default:
+		return err
+	}
+	return &extension_kit.ExtensionError{
+		Title:  title,
+		Detail: new(err.Error()),
+	}
+}
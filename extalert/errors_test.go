@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"errors"
+	"fmt"
+	"github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-splunk-platform/config"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewSplunkError(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		kind       error
+		message    string
+		title      string
+	}{
+		{
+			name:       "authentication failure",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"messages":[{"type":"WARN","text":"call not properly authenticated"}]}`,
+			kind:       ErrAuthentication,
+			message:    "unexpected status code 401. WARN: call not properly authenticated",
+			title:      "Splunk rejected the credentials",
+		},
+		{
+			name:       "permission denied",
+			statusCode: http.StatusForbidden,
+			body:       `{"messages":[{"type":"ERROR","text":"You do not have permission to perform this operation"}]}`,
+			kind:       ErrPermissionDenied,
+			message:    "unexpected status code 403. ERROR: You do not have permission to perform this operation",
+			title:      "Permission denied by Splunk",
+		},
+		{
+			name:       "not found",
+			statusCode: http.StatusNotFound,
+			body:       `{"messages":[{"type":"ERROR","text":"Unknown sid."}]}`,
+			kind:       ErrNotFound,
+			message:    "unexpected status code 404. ERROR: Unknown sid.",
+			title:      "Not found in Splunk",
+		},
+		{
+			name:       "search quota exceeded",
+			statusCode: http.StatusServiceUnavailable,
+			body:       `{"messages":[{"type":"FATAL","text":"Search not executed: The maximum number of concurrent historical searches for this user based on their role quota has been reached."}]}`,
+			kind:       ErrSearchQuotaExceeded,
+			message:    "unexpected status code 503. FATAL: Search not executed: The maximum number of concurrent historical searches for this user based on their role quota has been reached.",
+			title:      "Splunk search quota exceeded",
+		},
+		{
+			name:       "unstructured response",
+			statusCode: http.StatusBadGateway,
+			body:       `<html>Bad Gateway</html>`,
+			message:    "unexpected status code 502. full response: <html>Bad Gateway</html>",
+			title:      "Splunk request failed with status code 502",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newSplunkError(tt.statusCode, []byte(tt.body))
+
+			require.Equal(t, tt.message, err.Error())
+			require.Equal(t, tt.title, err.Title())
+			if tt.kind != nil {
+				require.ErrorIs(t, err, tt.kind)
+			} else {
+				require.Nil(t, errors.Unwrap(err))
+			}
+		})
+	}
+}
+
+func TestSplunkClient_ReturnsSplunkError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"messages":[{"type":"ERROR","text":"You do not have permission to perform this operation"}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AccessToken: "token"})
+	require.NoError(t, err)
+	_, err = c.Alerts(t.Context())
+
+	var splunkErr *SplunkError
+	require.ErrorAs(t, err, &splunkErr)
+	require.Equal(t, http.StatusForbidden, splunkErr.StatusCode)
+	require.ErrorIs(t, err, ErrPermissionDenied)
+}
+
+func TestToExtensionError(t *testing.T) {
+	splunkErr := fmt.Errorf("failed to log in: %w", newSplunkError(http.StatusUnauthorized, []byte(`{"messages":[{"type":"WARN","text":"Login failed"}]}`)))
+
+	var extensionErr *extension_kit.ExtensionError
+	require.ErrorAs(t, toExtensionError(splunkErr), &extensionErr)
+	require.Equal(t, "Splunk rejected the credentials", extensionErr.Title)
+	require.Equal(t, "failed to log in: unexpected status code 401. WARN: Login failed", *extensionErr.Detail)
+
+	otherErr := errors.New("connection refused")
+	require.Same(t, otherErr, toExtensionError(otherErr))
+	require.NoError(t, toExtensionError(nil))
+}
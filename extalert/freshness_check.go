@@ -0,0 +1,437 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+	"strings"
+	"time"
+)
+
+type FreshnessCheckAction struct {
+	Clients ClientResolver[SearchClient]
+}
+
+var (
+	_ action_kit_sdk.Action[FreshnessCheckState]           = (*FreshnessCheckAction)(nil)
+	_ action_kit_sdk.ActionWithStatus[FreshnessCheckState] = (*FreshnessCheckAction)(nil)
+	_ action_kit_sdk.ActionWithStop[FreshnessCheckState]   = (*FreshnessCheckAction)(nil)
+)
+
+type FreshnessCheckState struct {
+	Instance   string
+	Index      string
+	Sourcetype string
+	Host       string
+	Mode       string
+	// MaxFreshness is the largest acceptable indexing lag or age of the latest event.
+	MaxFreshness time.Duration
+	// Window is how far back each search looks for events.
+	Window         time.Duration
+	Start          time.Time
+	End            time.Time
+	FailEarly      bool
+	DeviationTitle string
+	// Sid is the search job currently in flight, empty if no job is running.
+	Sid string
+	// SearchLatest is the time the search job in flight was dispatched at.
+	SearchLatest time.Time
+}
+
+const (
+	// freshnessModeLag measures the largest delay between the time of an event and the time it was indexed among the
+	// most recently indexed events.
+	freshnessModeLag = "lag"
+	// freshnessModeAge measures how long ago the latest event happened.
+	freshnessModeAge = "age"
+	// freshnessLagEvents is how many of the most recently indexed events the indexing lag is measured on, so the check
+	// recovers as soon as ingestion catches up instead of once the delayed events left the search window.
+	freshnessLagEvents = 10
+)
+
+func NewFreshnessCheckAction(clients ClientResolver[SearchClient]) action_kit_sdk.Action[FreshnessCheckState] {
+	return &FreshnessCheckAction{
+		Clients: clients,
+	}
+}
+
+func (a *FreshnessCheckAction) NewEmptyState() FreshnessCheckState {
+	return FreshnessCheckState{}
+}
+
+func (a *FreshnessCheckAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.freshness-check", IndexTargetType),
+		Label:       "Ingestion Freshness Check",
+		Description: "Check that events keep arriving in an index without delay.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        new(indexIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType:          IndexTargetType,
+			QuantityRestriction: extutil.Ptr(action_kit_api.ExactlyOne),
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "Index name",
+					Description: new("Find index by name"),
+					Query:       attributeIndexName + "=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Splunk"),
+		Category:    new("Monitoring"),
+		Kind:        action_kit_api.Check,
+		TimeControl: action_kit_api.TimeControlInternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("30s"),
+				Required:     new(true),
+			},
+			{
+				Name:         "mode",
+				Label:        "Freshness",
+				Description:  new(fmt.Sprintf("What to measure: the largest delay between the time of an event and the time it was indexed among the %d most recently indexed events, or how long ago the latest event happened.", freshnessLagEvents)),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new(freshnessModeLag),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Indexing lag",
+						Value: freshnessModeLag,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Age of the latest event",
+						Value: freshnessModeAge,
+					},
+				}),
+				Required: new(true),
+			},
+			{
+				Name:         "maxFreshness",
+				Label:        "Maximum Lag or Age",
+				Description:  new("The check fails if the indexing lag or the age of the latest event exceeds this duration."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("1m"),
+				Required:     new(true),
+			},
+			{
+				Name:        "sourcetype",
+				Label:       "Sourcetype",
+				Description: new("Only consider events of this sourcetype. Supports wildcards."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    new(false),
+			},
+			{
+				Name:        "host",
+				Label:       "Host",
+				Description: new("Only consider events of this host. Supports wildcards."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    new(false),
+			},
+			{
+				Name:         "window",
+				Label:        "Search Window",
+				Description:  new("How far back each search looks for events. If no event is found within the window, the check fails."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("5m"),
+				Advanced:     new(true),
+				Required:     new(true),
+			},
+			{
+				Name:         "failEarly",
+				Label:        "Fail early",
+				Description:  new("If enabled, the check fails as soon as the freshness is exceeded. If disabled, the check keeps searching for the whole duration and only fails at the end of the step."),
+				Type:         action_kit_api.ActionParameterTypeBoolean,
+				DefaultValue: new("true"),
+				Advanced:     new(true),
+				Required:     new(false),
+			},
+		},
+		Widgets: new([]action_kit_api.Widget{
+			action_kit_api.StateOverTimeWidget{
+				Type:  action_kit_api.ComSteadybitWidgetStateOverTime,
+				Title: "Ingestion Freshness",
+				Identity: action_kit_api.StateOverTimeWidgetIdentityConfig{
+					From: searchMetricId,
+				},
+				Label: action_kit_api.StateOverTimeWidgetLabelConfig{
+					From: searchMetricLabel,
+				},
+				State: action_kit_api.StateOverTimeWidgetStateConfig{
+					From: searchMetricState,
+				},
+				Tooltip: action_kit_api.StateOverTimeWidgetTooltipConfig{
+					From: searchMetricTooltip,
+				},
+				Value: new(action_kit_api.StateOverTimeWidgetValueConfig{
+					Hide: new(true),
+				}),
+			},
+			action_kit_api.LineChartWidget{
+				Type:  action_kit_api.ComSteadybitWidgetLineChart,
+				Title: "Ingestion Freshness (s)",
+				Identity: action_kit_api.LineChartWidgetIdentityConfig{
+					MetricName: freshnessMetricName,
+					From:       searchMetricSeries,
+					Mode:       action_kit_api.ComSteadybitWidgetLineChartIdentityModeWidgetPerValue,
+				},
+				Tooltip: new(action_kit_api.LineChartWidgetTooltipConfig{
+					MetricValueTitle: new("Seconds"),
+				}),
+			},
+		}),
+		Status: new(action_kit_api.MutatingEndpointReferenceWithCallInterval{
+			CallInterval: new("1s"),
+		}),
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *FreshnessCheckAction) Prepare(ctx context.Context, state *FreshnessCheckState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	index := request.Target.Attributes[attributeIndexName]
+	if len(index) == 0 {
+		return nil, fmt.Errorf("target is missing the index name attribute")
+	}
+
+	mode := extutil.ToString(request.Config["mode"])
+	switch mode {
+	case freshnessModeLag, freshnessModeAge:
+	default:
+		return nil, fmt.Errorf("unsupported freshness mode %q", mode)
+	}
+
+	maxFreshness := time.Duration(extutil.ToInt64(request.Config["maxFreshness"])) * time.Millisecond
+	if maxFreshness <= 0 {
+		return nil, fmt.Errorf("maximum lag or age parameter must be positive")
+	}
+	window := time.Duration(extutil.ToInt64(request.Config["window"])) * time.Millisecond
+	if window <= 0 {
+		return nil, fmt.Errorf("search window parameter must be positive")
+	}
+
+	start := time.Now()
+	end := start.Add(time.Duration(extutil.ToInt64(request.Config["duration"])) * time.Millisecond)
+
+	if instance := request.Target.Attributes[attributeInstance]; len(instance) > 0 {
+		state.Instance = instance[0]
+	}
+	state.Index = index[0]
+	state.Sourcetype = strings.TrimSpace(extutil.ToString(request.Config["sourcetype"]))
+	state.Host = strings.TrimSpace(extutil.ToString(request.Config["host"]))
+	state.Mode = mode
+	state.MaxFreshness = maxFreshness
+	state.Window = window
+	state.Start = start
+	state.End = end
+	state.FailEarly = true
+	if request.Config["failEarly"] != nil {
+		state.FailEarly = extutil.ToBool(request.Config["failEarly"])
+	}
+
+	if err := preflight(ctx, a.Clients, state.Instance, capabilitySearch); err != nil {
+		return nil, err
+	}
+
+	log.Trace().Any("state", state).Msg("freshness check action state")
+
+	return nil, nil
+}
+
+func (a *FreshnessCheckAction) Start(ctx context.Context, state *FreshnessCheckState) (*action_kit_api.StartResult, error) {
+	client, err := a.Clients(state.Instance)
+	if err != nil {
+		return nil, err
+	}
+	statusResult, err := checkFreshness(ctx, state, client)
+	if statusResult == nil {
+		return nil, toExtensionError(err)
+	}
+	return &action_kit_api.StartResult{
+		Error:   statusResult.Error,
+		Metrics: statusResult.Metrics,
+	}, toExtensionError(err)
+}
+
+func (a *FreshnessCheckAction) Status(ctx context.Context, state *FreshnessCheckState) (*action_kit_api.StatusResult, error) {
+	client, err := a.Clients(state.Instance)
+	if err != nil {
+		return nil, err
+	}
+	statusResult, err := checkFreshness(ctx, state, client)
+	return statusResult, toExtensionError(err)
+}
+
+// Stop cancels the search job in flight, so an aborted check doesn't leave it running in Splunk.
+func (a *FreshnessCheckAction) Stop(ctx context.Context, state *FreshnessCheckState) (*action_kit_api.StopResult, error) {
+	cancelSearch(ctx, a.Clients, state.Instance, &state.Sid)
+	return nil, nil
+}
+
+// checkFreshness runs one search job after the other, each covering the search window before the job was
+// dispatched. The check completes once a job dispatched after the end of the step has been evaluated.
+func checkFreshness(ctx context.Context, state *FreshnessCheckState, client SearchClient) (*action_kit_api.StatusResult, error) {
+	now := time.Now()
+
+	if state.Sid == "" {
+		sid, err := client.DispatchSearch(ctx, freshnessQuery(state), now.Add(-state.Window), now)
+		if err != nil {
+			return nil, err
+		}
+		state.Sid = sid
+		state.SearchLatest = now
+		return &action_kit_api.StatusResult{Completed: false}, nil
+	}
+
+	job, err := client.SearchJob(ctx, state.Sid)
+	if err != nil {
+		return nil, err
+	}
+	if job.IsFailed || job.DispatchState == "FAILED" {
+		return nil, fmt.Errorf("search job %s failed", state.Sid)
+	}
+	if !job.IsDone {
+		return &action_kit_api.StatusResult{Completed: false}, nil
+	}
+
+	results, err := client.SearchResults(ctx, state.Sid)
+	if err != nil {
+		return nil, err
+	}
+	state.Sid = ""
+
+	completed := state.SearchLatest.After(state.End)
+	freshness, found := freshnessValue(state, results)
+	fresh := found && freshness <= state.MaxFreshness.Seconds()
+
+	var checkError *action_kit_api.ActionKitError
+	if !fresh {
+		title := freshnessDeviationTitle(state, freshness, found)
+		if state.FailEarly {
+			checkError = new(action_kit_api.ActionKitError{
+				Title:  title,
+				Status: extutil.Ptr(action_kit_api.Failed),
+			})
+		} else if state.DeviationTitle == "" {
+			state.DeviationTitle = title
+		}
+	}
+	if !state.FailEarly && completed && state.DeviationTitle != "" {
+		checkError = new(action_kit_api.ActionKitError{
+			Title:  state.DeviationTitle,
+			Status: extutil.Ptr(action_kit_api.Failed),
+		})
+	}
+
+	return &action_kit_api.StatusResult{
+		Completed: completed,
+		Error:     checkError,
+		Metrics:   new(toFreshnessMetrics(state, freshness, found, fresh, now)),
+	}, nil
+}
+
+// freshnessQuery aggregates the events of the index matching the sourcetype and host filters into their count, the
+// largest indexing lag and the time of the latest event. The indexing lag is only measured on the most recently indexed
+// events.
+func freshnessQuery(state *FreshnessCheckState) string {
+	filters := []string{"index=" + splQuote(state.Index)}
+	if state.Sourcetype != "" {
+		filters = append(filters, "sourcetype="+splQuote(state.Sourcetype))
+	}
+	if state.Host != "" {
+		filters = append(filters, "host="+splQuote(state.Host))
+	}
+	query := "search " + strings.Join(filters, " ")
+	if state.Mode == freshnessModeLag {
+		query += fmt.Sprintf(" | sort %d - _indextime", freshnessLagEvents)
+	}
+	return query + " | eval lag=_indextime-_time | stats count, max(lag) as max_lag, max(_time) as latest_time"
+}
+
+// splQuote quotes a value for a search term. Wildcards keep working in quoted values.
+func splQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// freshnessValue returns the indexing lag or the age of the latest event in seconds. It is not found if no event
+// arrived within the search window.
+func freshnessValue(state *FreshnessCheckState, results []map[string]any) (float64, bool) {
+	if len(results) == 0 {
+		return 0, false
+	}
+	if count, ok := toFloat(results[0]["count"]); !ok || count == 0 {
+		return 0, false
+	}
+	if state.Mode == freshnessModeAge {
+		latest, ok := toFloat(results[0]["latest_time"])
+		if !ok {
+			return 0, false
+		}
+		return max(0, float64(state.SearchLatest.UnixMilli())/1000-latest), true
+	}
+	return toFloat(results[0]["max_lag"])
+}
+
+func freshnessLabel(state *FreshnessCheckState) string {
+	if state.Mode == freshnessModeAge {
+		return "Age of the latest event"
+	}
+	return fmt.Sprintf("Indexing lag of the latest %d events", freshnessLagEvents)
+}
+
+func freshnessDeviationTitle(state *FreshnessCheckState, freshness float64, found bool) string {
+	if !found {
+		return fmt.Sprintf("No events arrived in index %q within the last %s.", state.Index, state.Window)
+	}
+	return fmt.Sprintf("%s of index %q is %s, exceeding %s.", freshnessLabel(state), state.Index, formatSeconds(freshness), state.MaxFreshness)
+}
+
+func formatSeconds(seconds float64) string {
+	return (time.Duration(seconds * float64(time.Second))).Round(time.Second).String()
+}
+
+func toFreshnessMetrics(state *FreshnessCheckState, freshness float64, found bool, fresh bool, now time.Time) []action_kit_api.Metric {
+	label := freshnessLabel(state)
+
+	tooltip := fmt.Sprintf("No events within the last %s", state.Window)
+	if found {
+		tooltip = fmt.Sprintf("%s: %s", label, formatSeconds(freshness))
+	}
+
+	metricState := "danger"
+	if fresh {
+		metricState = "success"
+	}
+
+	metrics := []action_kit_api.Metric{
+		{
+			Name: new("Splunk Ingestion Freshness"),
+			Metric: map[string]string{
+				searchMetricId:      state.Index,
+				searchMetricLabel:   fmt.Sprintf("%s <= %s", label, state.MaxFreshness),
+				searchMetricState:   metricState,
+				searchMetricTooltip: tooltip,
+			},
+			Timestamp: now,
+			Value:     freshness,
+		},
+	}
+	if found {
+		metrics = append(metrics, action_kit_api.Metric{
+			Name: new(freshnessMetricName),
+			Metric: map[string]string{
+				searchMetricSeries: label,
+			},
+			Timestamp: now,
+			Value:     freshness,
+		})
+	}
+	return metrics
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFreshnessCheckAction_Describe_NoError(t *testing.T) {
+	action := NewFreshnessCheckAction(resolveTo[SearchClient](MockSearchClient{}))
+
+	description := action.Describe()
+
+	require.NotNil(t, description)
+	require.Equal(t, IndexTargetType, description.TargetSelection.TargetType)
+}
+
+func TestFreshnessCheckAction_Prepare(t *testing.T) {
+	action := NewFreshnessCheckAction(resolveTo[SearchClient](MockSearchClient{}))
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				attributeIndexName: {"main"},
+				attributeInstance:  {"production"},
+			},
+		},
+		Config: map[string]any{
+			"duration":     1000,
+			"mode":         freshnessModeAge,
+			"maxFreshness": 60000,
+			"window":       300000,
+			"sourcetype":   " access_combined ",
+		},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "production", state.Instance)
+	require.Equal(t, "main", state.Index)
+	require.Equal(t, "access_combined", state.Sourcetype)
+	require.Equal(t, freshnessModeAge, state.Mode)
+	require.Equal(t, time.Minute, state.MaxFreshness)
+	require.Equal(t, 5*time.Minute, state.Window)
+	require.Greater(t, state.End, state.Start)
+	require.True(t, state.FailEarly)
+}
+
+func TestFreshnessCheckAction_Prepare_invalidMode(t *testing.T) {
+	action := NewFreshnessCheckAction(resolveTo[SearchClient](MockSearchClient{}))
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{attributeIndexName: {"main"}},
+		},
+		Config: map[string]any{
+			"duration":     1000,
+			"mode":         "latency",
+			"maxFreshness": 60000,
+			"window":       300000,
+		},
+	})
+
+	require.EqualError(t, err, `unsupported freshness mode "latency"`)
+}
+
+func TestFreshnessCheckAction_Stop_cancelsJobInFlight(t *testing.T) {
+	var cancelled []string
+	action := NewFreshnessCheckAction(resolveTo[SearchClient](MockSearchClient{cancelled: &cancelled})).(action_kit_sdk.ActionWithStop[FreshnessCheckState])
+	state := newFreshnessState(freshnessModeLag, true)
+	state.Sid = "sid"
+
+	_, err := action.Stop(t.Context(), state)
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"sid"}, cancelled)
+	require.Empty(t, state.Sid)
+}
+
+func TestFreshnessQuery(t *testing.T) {
+	require.Equal(t, `search index="main" | eval lag=_indextime-_time | stats count, max(lag) as max_lag, max(_time) as latest_time`,
+		freshnessQuery(&FreshnessCheckState{Index: "main", Mode: freshnessModeAge}))
+	require.Equal(t, `search index="main" sourcetype="access_*" host="web \"01\"" | eval lag=_indextime-_time | stats count, max(lag) as max_lag, max(_time) as latest_time`,
+		freshnessQuery(&FreshnessCheckState{Index: "main", Mode: freshnessModeAge, Sourcetype: "access_*", Host: `web "01"`}))
+}
+
+func TestFreshnessQuery_lagOfLatestEvents(t *testing.T) {
+	require.Equal(t, `search index="main" | sort 10 - _indextime | eval lag=_indextime-_time | stats count, max(lag) as max_lag, max(_time) as latest_time`,
+		freshnessQuery(&FreshnessCheckState{Index: "main", Mode: freshnessModeLag}))
+}
+
+func newFreshnessState(mode string, failEarly bool) *FreshnessCheckState {
+	now := time.Now()
+	return &FreshnessCheckState{
+		Index:        "main",
+		Mode:         mode,
+		MaxFreshness: time.Minute,
+		Window:       5 * time.Minute,
+		Start:        now,
+		End:          now.Add(time.Minute),
+		FailEarly:    failEarly,
+	}
+}
+
+func runFreshnessSearch(t *testing.T, state *FreshnessCheckState, results []map[string]any) *action_kit_api.StatusResult {
+	client := MockSearchClient{sid: "sid", job: SearchJobContent{IsDone: true}, results: results}
+	result, err := checkFreshness(t.Context(), state, client)
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.Equal(t, "sid", state.Sid)
+
+	result, err = checkFreshness(t.Context(), state, client)
+	require.NoError(t, err)
+	require.Empty(t, state.Sid)
+	return result
+}
+
+func TestCheckFreshness_lagWithinLimit(t *testing.T) {
+	state := newFreshnessState(freshnessModeLag, true)
+
+	result := runFreshnessSearch(t, state, []map[string]any{{"count": "12", "max_lag": "3.5"}})
+
+	require.Nil(t, result.Error)
+	require.Equal(t, 3.5, (*result.Metrics)[0].Value)
+	require.Equal(t, "success", (*result.Metrics)[0].Metric[searchMetricState])
+}
+
+func TestCheckFreshness_lagExceeded(t *testing.T) {
+	state := newFreshnessState(freshnessModeLag, true)
+
+	result := runFreshnessSearch(t, state, []map[string]any{{"count": "12", "max_lag": "95"}})
+
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Indexing lag of the latest 10 events of index "main" is 1m35s, exceeding 1m0s.`, result.Error.Title)
+	require.Equal(t, "danger", (*result.Metrics)[0].Metric[searchMetricState])
+}
+
+func TestCheckFreshness_ageExceeded(t *testing.T) {
+	state := newFreshnessState(freshnessModeAge, true)
+	latest := float64(time.Now().Add(-2*time.Minute).UnixMilli()) / 1000
+
+	result := runFreshnessSearch(t, state, []map[string]any{{"count": "1", "max_lag": "1", "latest_time": strconv.FormatFloat(latest, 'f', 3, 64)}})
+
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Age of the latest event of index "main" is 2m0s, exceeding 1m0s.`, result.Error.Title)
+}
+
+func TestCheckFreshness_noEvents(t *testing.T) {
+	state := newFreshnessState(freshnessModeLag, true)
+
+	result := runFreshnessSearch(t, state, []map[string]any{{"count": "0"}})
+
+	require.NotNil(t, result.Error)
+	require.Equal(t, `No events arrived in index "main" within the last 5m0s.`, result.Error.Title)
+	require.Len(t, *result.Metrics, 1)
+}
+
+func TestCheckFreshness_failsAtEndIfNotFailEarly(t *testing.T) {
+	state := newFreshnessState(freshnessModeLag, false)
+
+	result := runFreshnessSearch(t, state, []map[string]any{{"count": "12", "max_lag": "95"}})
+	require.Nil(t, result.Error)
+
+	state.End = time.Now().Add(-time.Second)
+	result = runFreshnessSearch(t, state, []map[string]any{{"count": "12", "max_lag": "1"}})
+	require.True(t, result.Completed)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Indexing lag of the latest 10 events of index "main" is 1m35s, exceeding 1m0s.`, result.Error.Title)
+}
@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/extension-kit/exthealth"
+	"sync"
+	"time"
+)
+
+type HealthClient interface {
+	ServerInfo(ctx context.Context) (*ServerInfoContent, error)
+	CurrentContext(ctx context.Context) (*CurrentContextContent, error)
+}
+
+const healthCheckTimeout = 10 * time.Second
+
+// HealthMonitor ties the readiness of the extension to the reachability of Splunk. The extension is ready while at
+// least one instance is reachable, so a single unreachable instance doesn't take down the checks of the others.
+type HealthMonitor struct {
+	Instances []string
+	Clients   ClientResolver[HealthClient]
+	SetReady  func(ready bool)
+	// FailureThreshold is the number of consecutive failed checks after which a reachable instance is considered
+	// unreachable, so a single slow or failed request doesn't make the extension not ready.
+	FailureThreshold int
+
+	mu        sync.Mutex
+	reachable map[string]bool
+	failures  map[string]int
+}
+
+func NewHealthMonitor(instances []string, clients ClientResolver[HealthClient], failureThreshold int) *HealthMonitor {
+	return &HealthMonitor{
+		Instances:        instances,
+		Clients:          clients,
+		SetReady:         exthealth.SetReady,
+		FailureThreshold: failureThreshold,
+		reachable:        make(map[string]bool, len(instances)),
+		failures:         make(map[string]int, len(instances)),
+	}
+}
+
+// SelfTest connects to all instances and logs the version of Splunk and the user, roles and capabilities the extension
+// is authenticated with, so misconfigurations show up right at startup.
+func (m *HealthMonitor) SelfTest(ctx context.Context) {
+	for _, instance := range m.Instances {
+		m.selfTest(ctx, instance)
+	}
+	m.updateReadiness()
+}
+
+func (m *HealthMonitor) selfTest(ctx context.Context, instance string) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	client, err := m.Clients(instance)
+	if err != nil {
+		log.Error().Err(err).Str("instance", instance).Msg("Failed to connect to Splunk")
+		m.setReachable(instance, false)
+		return
+	}
+	info, err := client.ServerInfo(ctx)
+	if err != nil {
+		log.Error().Err(err).Str("instance", instance).Msg("Failed to connect to Splunk")
+		m.setReachable(instance, false)
+		return
+	}
+	m.setReachable(instance, true)
+
+	current, err := client.CurrentContext(ctx)
+	if err != nil {
+		log.Warn().Err(err).Str("instance", instance).Str("version", info.Version).Msg("Connected to Splunk, but failed to retrieve the current user")
+		return
+	}
+	log.Info().
+		Str("instance", instance).
+		Str("serverName", info.ServerName).
+		Str("version", info.Version).
+		Str("user", current.Username).
+		Strs("roles", current.Roles).
+		Strs("capabilities", current.Capabilities).
+		Msg("Connected to Splunk")
+}
+
+// Start checks the reachability of all instances in the given interval. A zero interval disables the checks and makes
+// the extension ready regardless of Splunk, e.g. for experiments making Splunk itself unavailable.
+func (m *HealthMonitor) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		m.SetReady(true)
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.check(ctx)
+			}
+		}
+	}()
+}
+
+func (m *HealthMonitor) check(ctx context.Context) {
+	for _, instance := range m.Instances {
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		client, err := m.Clients(instance)
+		if err == nil {
+			_, err = client.ServerInfo(checkCtx)
+		}
+		cancel()
+
+		if err != nil && m.recordFailure(instance) < m.FailureThreshold {
+			log.Debug().Err(err).Str("instance", instance).Msg("Failed to reach Splunk")
+			continue
+		}
+		if wasReachable := m.setReachable(instance, err == nil); wasReachable != (err == nil) {
+			if err != nil {
+				log.Warn().Err(err).Str("instance", instance).Msg("Splunk became unreachable")
+			} else {
+				log.Info().Str("instance", instance).Msg("Splunk is reachable again")
+			}
+		}
+	}
+	m.updateReadiness()
+}
+
+// recordFailure counts a failed check of an instance and returns the number of consecutive failures.
+func (m *HealthMonitor) recordFailure(instance string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures[instance]++
+	return m.failures[instance]
+}
+
+// setReachable records the reachability of an instance and returns the previous one. A reachable instance resets the
+// consecutive failures.
+func (m *HealthMonitor) setReachable(instance string, reachable bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	previous := m.reachable[instance]
+	m.reachable[instance] = reachable
+	if reachable {
+		m.failures[instance] = 0
+	}
+	return previous
+}
+
+func (m *HealthMonitor) updateReadiness() {
+	m.mu.Lock()
+	ready := false
+	for _, reachable := range m.reachable {
+		ready = ready || reachable
+	}
+	m.mu.Unlock()
+	m.SetReady(ready)
+}
@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+type MockHealthClient struct {
+	err error
+}
+
+func (c *MockHealthClient) ServerInfo(_ context.Context) (*ServerInfoContent, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &ServerInfoContent{ServerName: "splunk", Version: "9.4.2"}, nil
+}
+
+func (c *MockHealthClient) CurrentContext(_ context.Context) (*CurrentContextContent, error) {
+	return &CurrentContextContent{Username: "steadybit", Roles: []string{"user"}, Capabilities: []string{"search"}}, nil
+}
+
+func newTestHealthMonitor(clients map[string]*MockHealthClient, instances ...string) (*HealthMonitor, *[]bool) {
+	var readiness []bool
+	monitor := NewHealthMonitor(instances, func(instance string) (HealthClient, error) {
+		return clients[instance], nil
+	}, 1)
+	monitor.SetReady = func(ready bool) {
+		readiness = append(readiness, ready)
+	}
+	return monitor, &readiness
+}
+
+func TestHealthMonitor_ReadyWhenReachable(t *testing.T) {
+	client := &MockHealthClient{}
+	monitor, readiness := newTestHealthMonitor(map[string]*MockHealthClient{"default": client}, "default")
+
+	monitor.SelfTest(t.Context())
+	require.Equal(t, []bool{true}, *readiness)
+
+	client.err = errors.New("connection refused")
+	monitor.check(t.Context())
+	require.Equal(t, []bool{true, false}, *readiness)
+
+	client.err = nil
+	monitor.check(t.Context())
+	require.Equal(t, []bool{true, false, true}, *readiness)
+}
+
+func TestHealthMonitor_NotReadyAfterConsecutiveFailures(t *testing.T) {
+	client := &MockHealthClient{}
+	monitor, readiness := newTestHealthMonitor(map[string]*MockHealthClient{"default": client}, "default")
+	monitor.FailureThreshold = 3
+
+	monitor.SelfTest(t.Context())
+	client.err = errors.New("connection refused")
+	monitor.check(t.Context())
+	monitor.check(t.Context())
+	require.Equal(t, []bool{true, true, true}, *readiness)
+
+	client.err = nil
+	monitor.check(t.Context())
+	client.err = errors.New("connection refused")
+	monitor.check(t.Context())
+	monitor.check(t.Context())
+	require.Equal(t, []bool{true, true, true, true, true, true}, *readiness, "a successful check resets the failures")
+
+	monitor.check(t.Context())
+	require.Equal(t, []bool{true, true, true, true, true, true, false}, *readiness)
+}
+
+func TestHealthMonitor_NotReadyWithRejectedCredentials(t *testing.T) {
+	client := &MockHealthClient{err: newSplunkError(http.StatusUnauthorized, nil)}
+	monitor, readiness := newTestHealthMonitor(map[string]*MockHealthClient{"default": client}, "default")
+
+	monitor.SelfTest(t.Context())
+
+	require.Equal(t, []bool{false}, *readiness)
+}
+
+func TestHealthMonitor_ReadyWhileAnyInstanceIsReachable(t *testing.T) {
+	clients := map[string]*MockHealthClient{
+		"production": {},
+		"staging":    {err: errors.New("connection refused")},
+	}
+	monitor, readiness := newTestHealthMonitor(clients, "production", "staging")
+
+	monitor.SelfTest(t.Context())
+
+	require.Equal(t, []bool{true}, *readiness)
+}
+
+func TestHealthMonitor_ReadyWithoutChecks(t *testing.T) {
+	client := &MockHealthClient{err: errors.New("connection refused")}
+	monitor, readiness := newTestHealthMonitor(map[string]*MockHealthClient{"default": client}, "default")
+
+	monitor.SelfTest(t.Context())
+	monitor.Start(t.Context(), 0)
+
+	require.Equal(t, []bool{false, true}, *readiness)
+}
@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-splunk-platform/config"
+	"strconv"
+	"time"
+)
+
+type IndexClient interface {
+	Indexes(ctx context.Context) ([]Entry, error)
+}
+
+type indexDiscovery struct {
+	Instances []string
+	Clients   ClientResolver[IndexClient]
+
+	targets instanceTargets
+}
+
+var (
+	_ discovery_kit_sdk.TargetDescriber    = (*indexDiscovery)(nil)
+	_ discovery_kit_sdk.AttributeDescriber = (*indexDiscovery)(nil)
+)
+
+func NewIndexDiscovery(instances []string, clients ClientResolver[IndexClient]) discovery_kit_sdk.TargetDiscovery {
+	discovery := newIndexDiscovery(instances, clients)
+	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
+		discovery_kit_sdk.WithRefreshTargetsNow(),
+		discovery_kit_sdk.WithRefreshTargetsInterval(context.Background(), 1*time.Minute),
+	)
+}
+
+func newIndexDiscovery(instances []string, clients ClientResolver[IndexClient]) *indexDiscovery {
+	return &indexDiscovery{
+		Instances: instances,
+		Clients:   clients,
+		targets:   newInstanceTargets("indexes"),
+	}
+}
+
+func (d *indexDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
+	return discovery_kit_api.DiscoveryDescription{
+		Id: IndexTargetType,
+		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
+			CallInterval: new("1m"),
+		},
+	}
+}
+
+func (d *indexDiscovery) DescribeTarget() discovery_kit_api.TargetDescription {
+	return discovery_kit_api.TargetDescription{
+		Id:       IndexTargetType,
+		Label:    discovery_kit_api.PluralLabel{One: "Splunk Index", Other: "Splunk Indexes"},
+		Category: new("monitoring"),
+		Version:  extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:     new(indexIcon),
+		Table: discovery_kit_api.Table{
+			Columns: []discovery_kit_api.Column{
+				{Attribute: attributeIndexName},
+				{Attribute: attributeIndexDatatype},
+				{Attribute: attributeIndexEventCount},
+				{Attribute: attributeInstance},
+			},
+			OrderBy: []discovery_kit_api.OrderBy{
+				{
+					Attribute: attributeIndexName,
+					Direction: "ASC",
+				},
+			},
+		},
+	}
+}
+
+func (d *indexDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDescription {
+	return []discovery_kit_api.AttributeDescription{
+		{
+			Attribute: attributeIndexName,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Index",
+				Other: "Indexes",
+			},
+		},
+		{
+			Attribute: attributeIndexDatatype,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Datatype",
+				Other: "Datatypes",
+			},
+		},
+		{
+			Attribute: attributeIndexCurrentSizeMB,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Current Size (MB)",
+				Other: "Current Sizes (MB)",
+			},
+		},
+		{
+			Attribute: attributeIndexEventCount,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Event Count",
+				Other: "Event Counts",
+			},
+		},
+		{
+			Attribute: attributeIndexEarliestTime,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Earliest Event Time",
+				Other: "Earliest Event Times",
+			},
+		},
+		{
+			Attribute: attributeIndexLatestTime,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Latest Event Time",
+				Other: "Latest Event Times",
+			},
+		},
+		{
+			Attribute: attributeIndexFrozenTimePeriod,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Frozen Time Period (s)",
+				Other: "Frozen Time Periods (s)",
+			},
+		},
+	}
+}
+
+func (d *indexDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return d.getAllIndexTargets(ctx)
+}
+
+func (d *indexDiscovery) getAllIndexTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	result, err := d.targets.discover(ctx, d.Instances, d.getIndexTargets)
+	if err != nil {
+		return result, err
+	}
+	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesIndex), nil
+}
+
+func (d *indexDiscovery) getIndexTargets(ctx context.Context, instance string) ([]discovery_kit_api.Target, error) {
+	client, err := d.Clients(instance)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := client.Indexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]discovery_kit_api.Target, 0, len(indexes))
+	for _, index := range indexes {
+		attributes := map[string][]string{
+			attributeIndexName:             {index.Name},
+			attributeIndexDatatype:         {index.Content.Datatype},
+			attributeIndexCurrentSizeMB:    {strconv.Itoa(int(index.Content.CurrentDBSizeMB))},
+			attributeIndexEventCount:       {strconv.Itoa(int(index.Content.TotalEventCount))},
+			attributeIndexFrozenTimePeriod: {strconv.Itoa(int(index.Content.FrozenTimePeriodInSecs))},
+			attributeInstance:              {instance},
+		}
+		// empty indexes have no events to take the time from
+		if index.Content.MinTime != "" {
+			attributes[attributeIndexEarliestTime] = []string{index.Content.MinTime}
+		}
+		if index.Content.MaxTime != "" {
+			attributes[attributeIndexLatestTime] = []string{index.Content.MaxTime}
+		}
+		result = append(result, discovery_kit_api.Target{
+			Id:         index.Id,
+			TargetType: IndexTargetType,
+			Label:      index.Name,
+			Attributes: attributes,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"fmt"
+	"github.com/steadybit/extension-splunk-platform/config"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIndexDiscovery_DiscoverTargets(t *testing.T) {
+	discovery := newIndexDiscovery([]string{"default"}, resolveTo[IndexClient](MockSplunkClient{
+		response: []Entry{
+			{Id: "index-main", Name: "main", Content: Content{
+				Datatype:               "event",
+				CurrentDBSizeMB:        12,
+				TotalEventCount:        3456,
+				MinTime:                "2025-01-01T00:00:00+00:00",
+				MaxTime:                "2025-06-01T12:00:00+00:00",
+				FrozenTimePeriodInSecs: 188697600,
+			}},
+			{Id: "index-metrics", Name: "metrics", Content: Content{Datatype: "metric"}},
+		},
+	}))
+
+	targets, err := discovery.getAllIndexTargets(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+
+	require.Equal(t, "index-main", targets[0].Id)
+	require.Equal(t, IndexTargetType, targets[0].TargetType)
+	require.Equal(t, "main", targets[0].Label)
+	require.Equal(t, map[string][]string{
+		attributeIndexName:             {"main"},
+		attributeIndexDatatype:         {"event"},
+		attributeIndexCurrentSizeMB:    {"12"},
+		attributeIndexEventCount:       {"3456"},
+		attributeIndexEarliestTime:     {"2025-01-01T00:00:00+00:00"},
+		attributeIndexLatestTime:       {"2025-06-01T12:00:00+00:00"},
+		attributeIndexFrozenTimePeriod: {"188697600"},
+		attributeInstance:              {"default"},
+	}, targets[0].Attributes)
+
+	require.Equal(t, "metrics", targets[1].Label)
+	require.Equal(t, []string{"metric"}, targets[1].Attributes[attributeIndexDatatype])
+	require.NotContains(t, targets[1].Attributes, attributeIndexEarliestTime)
+	require.NotContains(t, targets[1].Attributes, attributeIndexLatestTime)
+}
+
+func TestIndexDiscovery_DiscoverTargets_excludedAttributes(t *testing.T) {
+	config.Config.DiscoveryAttributesExcludesIndex = []string{"splunk.index.earliest*"}
+	defer func() {
+		config.Config.DiscoveryAttributesExcludesIndex = []string{}
+	}()
+
+	discovery := newIndexDiscovery([]string{"default"}, resolveTo[IndexClient](MockSplunkClient{
+		response: []Entry{
+			{Id: "index-main", Name: "main", Content: Content{MinTime: "2025-01-01T00:00:00+00:00"}},
+		},
+	}))
+
+	targets, err := discovery.getAllIndexTargets(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	require.NotContains(t, targets[0].Attributes, attributeIndexEarliestTime)
+	require.Contains(t, targets[0].Attributes, attributeIndexName)
+}
+
+func TestIndexDiscovery_DiscoverTargets_errorResponse(t *testing.T) {
+	discovery := newIndexDiscovery([]string{"default"}, resolveTo[IndexClient](MockSplunkClient{
+		err: fmt.Errorf("some error"),
+	}))
+
+	targets, err := discovery.getAllIndexTargets(context.Background())
+
+	require.Empty(t, targets)
+	require.Error(t, err)
+}
+
+func TestSplunkClient_Indexes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/services/data/indexes", r.URL.Path)
+		require.Equal(t, "all", r.URL.Query().Get("datatype"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"entry":[{"id":"index-main","name":"main","content":{"datatype":"event","currentDBSizeMB":12,"totalEventCount":"3456","minTime":"2025-01-01T00:00:00+00:00","frozenTimePeriodInSecs":188697600}}]}`))
+	}))
+	defer srv.Close()
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AccessToken: "token"})
+	require.NoError(t, err)
+
+	indexes, err := c.Indexes(t.Context())
+
+	require.NoError(t, err)
+	require.Len(t, indexes, 1)
+	require.Equal(t, "main", indexes[0].Name)
+	require.Equal(t, Count(12), indexes[0].Content.CurrentDBSizeMB)
+	require.Equal(t, Count(3456), indexes[0].Content.TotalEventCount)
+	require.Equal(t, Count(188697600), indexes[0].Content.FrozenTimePeriodInSecs)
+}
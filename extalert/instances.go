@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"fmt"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-splunk-platform/config"
+)
+
+// ClientResolver returns the client of the Splunk instance with the given name. An empty name resolves the only
+// configured instance, e.g. for targets discovered before multiple instances were configured. It is ambiguous and fails
+// if several instances are configured.
+type ClientResolver[T any] func(instance string) (T, error)
+
+// SplunkClients holds a client for each configured Splunk instance.
+type SplunkClients struct {
+	instances []string
+	clients   map[string]*SplunkClient
+}
+
+var (
+	_ AlertClient               = (*SplunkClient)(nil)
+	_ IndexClient               = (*SplunkClient)(nil)
+	_ SavedSearchClient         = (*SplunkClient)(nil)
+	_ AlertCheckClient          = (*SplunkClient)(nil)
+	_ AggregateAlertClient      = (*SplunkClient)(nil)
+	_ SearchClient              = (*SplunkClient)(nil)
+	_ SavedSearchDispatchClient = (*SplunkClient)(nil)
+	_ AlertDisableClient        = (*SplunkClient)(nil)
+	_ StreamClient              = (*SplunkClient)(nil)
+	_ HealthClient              = (*SplunkClient)(nil)
+)
+
+func NewSplunkClients(instances []config.Instance) (*SplunkClients, error) {
+	clients := &SplunkClients{
+		clients: make(map[string]*SplunkClient, len(instances)),
+	}
+	for _, instance := range instances {
+		client, err := NewSplunkClient(instance)
+		if err != nil {
+			return nil, fmt.Errorf("instance %q: %w", instance.Name, err)
+		}
+		clients.instances = append(clients.instances, instance.Name)
+		clients.clients[instance.Name] = client
+	}
+	return clients, nil
+}
+
+// Instances returns the names of the configured instances.
+func (c *SplunkClients) Instances() []string {
+	return c.instances
+}
+
+func (c *SplunkClients) Client(instance string) (*SplunkClient, error) {
+	if instance == "" {
+		if len(c.instances) != 1 {
+			return nil, fmt.Errorf("no splunk instance given, but %d instances are configured", len(c.instances))
+		}
+		instance = c.instances[0]
+	}
+	client, ok := c.clients[instance]
+	if !ok {
+		return nil, fmt.Errorf("splunk instance %q is not configured", instance)
+	}
+	return client, nil
+}
+
+// Resolver adapts the clients to the client interface T of an action or discovery. *SplunkClient implements all of
+// them, as asserted above.
+func Resolver[T any](clients *SplunkClients) ClientResolver[T] {
+	return func(instance string) (T, error) {
+		client, err := clients.Client(instance)
+		if err != nil {
+			var none T
+			return none, err
+		}
+		return any(client).(T), nil
+	}
+}
+
+// instanceParameter selects the Splunk instance of actions which don't operate on a discovered alert.
+func instanceParameter() action_kit_api.ActionParameter {
+	return action_kit_api.ActionParameter{
+		Name:        "instance",
+		Label:       "Splunk Instance",
+		Description: new("The Splunk instance to run against. Only optional if a single instance is configured."),
+		Type:        action_kit_api.ActionParameterTypeString,
+		Options: new([]action_kit_api.ParameterOption{
+			action_kit_api.ParameterOptionsFromTargetAttribute{
+				Attribute: attributeInstance,
+			},
+		}),
+		Advanced: new(true),
+		Required: new(false),
+	}
+}
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"github.com/steadybit/extension-splunk-platform/config"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSplunkClients_Resolver(t *testing.T) {
+	clients, err := NewSplunkClients([]config.Instance{
+		{Name: "production", ApiBaseUrl: "https://production:8089", AccessToken: "token"},
+		{Name: "staging", ApiBaseUrl: "https://staging:8089", AccessToken: "token"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"production", "staging"}, clients.Instances())
+
+	resolve := Resolver[AlertCheckClient](clients)
+
+	staging, err := resolve("staging")
+	require.NoError(t, err)
+	require.Equal(t, "https://staging:8089", staging.(*SplunkClient).client.BaseURL)
+
+	_, err = resolve("")
+	require.EqualError(t, err, "no splunk instance given, but 2 instances are configured")
+
+	_, err = resolve("unknown")
+	require.ErrorContains(t, err, `splunk instance "unknown" is not configured`)
+}
+
+func TestSplunkClients_Resolver_singleInstance(t *testing.T) {
+	clients, err := NewSplunkClients([]config.Instance{
+		{Name: "production", ApiBaseUrl: "https://production:8089", AccessToken: "token"},
+	})
+	require.NoError(t, err)
+
+	fallback, err := Resolver[AlertCheckClient](clients)("")
+	require.NoError(t, err)
+	require.Equal(t, "https://production:8089", fallback.(*SplunkClient).client.BaseURL)
+}
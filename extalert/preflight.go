@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// minSplunkVersion is the oldest supported Splunk version, as documented in the README.
+	minSplunkVersion = "9.4.2"
+	// preflightCacheTTL is how long the version and capabilities of an instance are reused by preflight checks.
+	preflightCacheTTL = 5 * time.Minute
+
+	capabilitySearch   = "search"
+	capabilityRtSearch = "rtsearch"
+	// capabilityListSavedSearches allows to read saved searches, including alerts and their fired alerts.
+	capabilityListSavedSearches = "list_saved_searches"
+	// capabilityScheduleSearch allows to create and update scheduled searches and alerts.
+	capabilityScheduleSearch = "schedule_search"
+
+	instanceTypeCloud = "cloud"
+)
+
+var (
+	ErrUnsupportedVersion  = errors.New("unsupported Splunk version")
+	ErrMissingCapabilities = errors.New("missing Splunk capabilities")
+)
+
+type PreflightClient interface {
+	Preflight(ctx context.Context, capabilities ...string) error
+}
+
+type preflightCache struct {
+	mu           sync.Mutex
+	serverInfo   ServerInfoContent
+	capabilities []string
+	checkedAt    time.Time
+}
+
+// Preflight verifies that the Splunk version is supported and the user has the given capabilities, so actions fail
+// before the experiment starts rather than mid-run.
+func (c *SplunkClient) Preflight(ctx context.Context, capabilities ...string) error {
+	info, granted, err := c.serverInfoAndCapabilities(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Splunk Cloud Platform uses its own versioning, e.g. 9.3.2411.107, and is always kept up to date.
+	if info.InstanceType != instanceTypeCloud {
+		if supported, ok := isSupportedVersion(info.Version); !ok {
+			log.Debug().Str("version", info.Version).Msg("Cannot tell whether the Splunk version is supported")
+		} else if !supported {
+			return fmt.Errorf("%w: Splunk %s is older than %s", ErrUnsupportedVersion, info.Version, minSplunkVersion)
+		}
+	}
+
+	var missing []string
+	for _, capability := range capabilities {
+		if !slices.Contains(granted, capability) {
+			missing = append(missing, capability)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: the user lacks the capabilities %s", ErrMissingCapabilities, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (c *SplunkClient) serverInfoAndCapabilities(ctx context.Context) (ServerInfoContent, []string, error) {
+	c.preflight.mu.Lock()
+	defer c.preflight.mu.Unlock()
+	if time.Since(c.preflight.checkedAt) < preflightCacheTTL {
+		return c.preflight.serverInfo, c.preflight.capabilities, nil
+	}
+
+	info, err := c.ServerInfo(ctx)
+	if err != nil {
+		return ServerInfoContent{}, nil, err
+	}
+	current, err := c.CurrentContext(ctx)
+	if err != nil {
+		return ServerInfoContent{}, nil, err
+	}
+	c.preflight.serverInfo = *info
+	c.preflight.capabilities = current.Capabilities
+	c.preflight.checkedAt = time.Now()
+	return c.preflight.serverInfo, c.preflight.capabilities, nil
+}
+
+// isSupportedVersion compares the major, minor and patch version with minSplunkVersion. ok is false if the version
+// can't be parsed.
+func isSupportedVersion(version string) (supported bool, ok bool) {
+	actual, ok := parseVersion(version)
+	if !ok {
+		return false, false
+	}
+	minimum, _ := parseVersion(minSplunkVersion)
+	return slices.Compare(actual, minimum) >= 0, true
+}
+
+func parseVersion(version string) ([]int, bool) {
+	parts := strings.Split(version, ".")
+	if len(parts) < 3 {
+		return nil, false
+	}
+	numbers := make([]int, 3)
+	for i := range numbers {
+		number, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return nil, false
+		}
+		numbers[i] = number
+	}
+	return numbers, true
+}
+
+// preflight runs the preflight checks of the client of the given instance.
+func preflight[T PreflightClient](ctx context.Context, clients ClientResolver[T], instance string, capabilities ...string) error {
+	client, err := clients(instance)
+	if err != nil {
+		return err
+	}
+	return toExtensionError(client.Preflight(ctx, capabilities...))
+}
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-splunk-platform/config"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// newPreflightServer serves the server info and the current context with the given version and capabilities.
+func newPreflightServer(requests *atomic.Int32, version string, capabilities string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /services/server/info", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"entry":[{"content":{"serverName":"splunk","version":%q}}]}`, version)
+	})
+	mux.HandleFunc("GET /services/authentication/current-context", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"entry":[{"content":{"username":"steadybit","capabilities":%s}}]}`, capabilities)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestPreflight_SupportedVersionAndCapabilities(t *testing.T) {
+	var requests atomic.Int32
+	srv := newPreflightServer(&requests, "10.0.1", `["search","rtsearch"]`)
+	defer srv.Close()
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AccessToken: "token"})
+	require.NoError(t, err)
+
+	require.NoError(t, c.Preflight(t.Context(), capabilitySearch, capabilityRtSearch))
+	require.NoError(t, c.Preflight(t.Context(), capabilitySearch))
+
+	require.Equal(t, int32(2), requests.Load(), "version and capabilities should be cached")
+}
+
+func TestPreflight_UnsupportedVersion(t *testing.T) {
+	var requests atomic.Int32
+	srv := newPreflightServer(&requests, "9.3.4", `["search"]`)
+	defer srv.Close()
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AccessToken: "token"})
+	require.NoError(t, err)
+
+	err = c.Preflight(t.Context())
+
+	require.ErrorIs(t, err, ErrUnsupportedVersion)
+	require.EqualError(t, err, "unsupported Splunk version: Splunk 9.3.4 is older than 9.4.2")
+}
+
+func TestPreflight_MissingCapabilities(t *testing.T) {
+	var requests atomic.Int32
+	srv := newPreflightServer(&requests, "9.4.2", `["search"]`)
+	defer srv.Close()
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AccessToken: "token"})
+	require.NoError(t, err)
+
+	err = c.Preflight(t.Context(), capabilitySearch, capabilityRtSearch)
+
+	require.ErrorIs(t, err, ErrMissingCapabilities)
+	require.EqualError(t, err, "missing Splunk capabilities: the user lacks the capabilities rtsearch")
+}
+
+func TestPreflight_CloudVersionIsNotChecked(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /services/server/info", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"entry":[{"content":{"serverName":"splunk","version":"9.3.2411.107","instance_type":"cloud"}}]}`))
+	})
+	mux.HandleFunc("GET /services/authentication/current-context", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"entry":[{"content":{"username":"steadybit","capabilities":["search"]}}]}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AccessToken: "token"})
+	require.NoError(t, err)
+
+	require.NoError(t, c.Preflight(t.Context(), capabilitySearch))
+	require.ErrorIs(t, c.Preflight(t.Context(), capabilityScheduleSearch), ErrMissingCapabilities)
+}
+
+func TestIsSupportedVersion(t *testing.T) {
+	tests := []struct {
+		version   string
+		supported bool
+		ok        bool
+	}{
+		{version: "9.4.2", supported: true, ok: true},
+		{version: "9.4.10", supported: true, ok: true},
+		{version: "10.0.0", supported: true, ok: true},
+		{version: "9.4.1", supported: false, ok: true},
+		{version: "9.10", supported: false, ok: false},
+		{version: "10.0.2503.1", supported: true, ok: true},
+		{version: "", supported: false, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			supported, ok := isSupportedVersion(tt.version)
+			require.Equal(t, tt.supported, supported)
+			require.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func TestStreamCheckAction_Prepare_failsPreflight(t *testing.T) {
+	client := MockStreamClient{preflightErr: fmt.Errorf("%w: the user lacks the capabilities rtsearch", ErrMissingCapabilities)}
+	action := NewStreamCheckAction(resolveTo[StreamClient](client))
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{
+			"duration":  1000,
+			"query":     "index=main",
+			"operator":  operatorGreaterThan,
+			"threshold": "0",
+		},
+		ExecutionId: uuid.New(),
+	})
+
+	var extensionErr *extension_kit.ExtensionError
+	require.ErrorAs(t, err, &extensionErr)
+	require.Equal(t, "Missing Splunk capabilities", extensionErr.Title)
+}
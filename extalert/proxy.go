@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"github.com/steadybit/extension-splunk-platform/config"
+	"golang.org/x/net/http/httpproxy"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// useProxy sends the requests through the proxy configured for the instance, except for hosts on its no-proxy list.
+// Without a configured proxy, the proxy environment variables apply.
+func useProxy(client *resty.Client, instance config.Instance) error {
+	if instance.ProxyUrl == "" {
+		return nil
+	}
+	proxyUrl, err := parseProxyUrl(instance)
+	if err != nil {
+		return err
+	}
+	transport, err := client.Transport()
+	if err != nil {
+		return err
+	}
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  proxyUrl.String(),
+		HTTPSProxy: proxyUrl.String(),
+		NoProxy:    strings.Join(instance.NoProxy, ","),
+	}).ProxyFunc()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+	return nil
+}
+
+func parseProxyUrl(instance config.Instance) (*url.URL, error) {
+	proxyUrl, err := url.Parse(instance.ProxyUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %w", err)
+	}
+	if proxyUrl.Host == "" {
+		return nil, fmt.Errorf("invalid proxy url %q, expected e.g. http://proxy:3128", instance.ProxyUrl)
+	}
+	if instance.ProxyUsername != "" {
+		proxyUrl.User = url.UserPassword(instance.ProxyUsername, instance.ProxyPassword)
+	}
+	return proxyUrl, nil
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"github.com/steadybit/extension-splunk-platform/config"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestNewSplunkClient_Proxy(t *testing.T) {
+	var requestedUrl, proxyAuthorization string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedUrl = r.URL.String()
+		proxyAuthorization = r.Header.Get("Proxy-Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"paging":{"total":1},"entry":[{"name":"alert"}]}`))
+	}))
+	defer proxy.Close()
+
+	c, err := NewSplunkClient(config.Instance{
+		ApiBaseUrl:    "http://splunk.example.com:8089",
+		AccessToken:   "token",
+		ProxyUrl:      proxy.URL,
+		ProxyUsername: "proxy-user",
+		ProxyPassword: "proxy-password",
+	})
+	require.NoError(t, err)
+	entries, err := c.Alerts(t.Context())
+
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Contains(t, requestedUrl, "http://splunk.example.com:8089/services/saved/searches")
+	// base64 of proxy-user:proxy-password
+	require.Equal(t, "Basic cHJveHktdXNlcjpwcm94eS1wYXNzd29yZA==", proxyAuthorization)
+}
+
+func TestNewSplunkClient_NoProxy(t *testing.T) {
+	c, err := NewSplunkClient(config.Instance{
+		ApiBaseUrl: "https://splunk.example.com:8089",
+		ProxyUrl:   "http://proxy.example.com:3128",
+		NoProxy:    []string{".internal.example.com", "10.0.0.0/8"},
+	})
+	require.NoError(t, err)
+	transport, err := c.client.Transport()
+	require.NoError(t, err)
+
+	proxyFor := func(rawUrl string) *url.URL {
+		u, err := url.Parse(rawUrl)
+		require.NoError(t, err)
+		proxyUrl, err := transport.Proxy(&http.Request{URL: u})
+		require.NoError(t, err)
+		return proxyUrl
+	}
+	require.Equal(t, "http://proxy.example.com:3128", proxyFor("https://splunk.example.com:8089").String())
+	require.Nil(t, proxyFor("https://splunk.internal.example.com:8089"))
+	require.Nil(t, proxyFor("https://10.1.2.3:8089"))
+}
+
+func TestNewSplunkClient_InvalidProxyUrl(t *testing.T) {
+	_, err := NewSplunkClient(config.Instance{ProxyUrl: "proxy:3128"})
+
+	require.ErrorContains(t, err, `invalid proxy url "proxy:3128"`)
+}
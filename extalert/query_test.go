@@ -5,8 +5,11 @@ package extalert
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/go-resty/resty/v2"
@@ -31,3 +34,76 @@ func TestQuery_StopsWhenPageReturnsNoEntries(t *testing.T) {
 	assert.Empty(t, entries)
 	assert.Equal(t, 1, calls, "query must stop after an empty page instead of looping")
 }
+
+func TestQuery_DecodesFiredAlertDetails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"paging":{"total":2},"entry":[` +
+			`{"name":"a","content":{"trigger_time":946684800,"severity":5,"sid":"sid-1","triggered_alerts":"3"}},` +
+			`{"name":"b","content":{"trigger_time":946684860,"severity":2,"sid":"sid-2","triggered_alerts":1}}]}`))
+	}))
+	defer srv.Close()
+
+	c := &SplunkClient{client: resty.New().SetBaseURL(srv.URL)}
+
+	entries, err := c.FiredAlerts(context.Background(), "/services/alerts")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, SeveritySevere, entries[0].Content.AlertSeverity())
+	assert.Equal(t, "sid-1", entries[0].Content.Sid)
+	assert.Equal(t, Count(3), entries[0].Content.TriggeredAlerts)
+	assert.Equal(t, Count(1), entries[1].Content.TriggeredAlerts)
+}
+
+func TestFiredAlertsSince_StopsPagingAtOlderAlerts(t *testing.T) {
+	var requests []*http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		entries := make([]string, 0, 30)
+		for i := range 30 {
+			entries = append(entries, fmt.Sprintf(`{"name":"%d","content":{"trigger_time":%d}}`, offset+i, 1000-offset-i))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"paging":{"total":1000},"entry":[%s]}`, strings.Join(entries, ","))
+	}))
+	defer srv.Close()
+
+	c := &SplunkClient{client: resty.New().SetBaseURL(srv.URL)}
+
+	entries, err := c.FiredAlertsSince(context.Background(), "/services/alerts", 950)
+	require.NoError(t, err)
+	assert.Len(t, entries, 51, "alerts fired at 1000 down to 950")
+	assert.Equal(t, int64(950), entries[len(entries)-1].Content.TriggerTime)
+	require.Len(t, requests, 2, "paging must stop at the first page reaching older alerts")
+	assert.Equal(t, "trigger_time", requests[0].URL.Query().Get("sort_key"))
+	assert.Equal(t, "desc", requests[0].URL.Query().Get("sort_dir"))
+}
+
+func TestLatestFiredAlert_RequestsOnlyNewestAlert(t *testing.T) {
+	var requests []*http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/services/alerts/never" {
+			_, _ = w.Write([]byte(`{"paging":{"total":0},"entry":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"paging":{"total":1000},"entry":[{"name":"newest","content":{"trigger_time":1000}}]}`))
+	}))
+	defer srv.Close()
+
+	c := &SplunkClient{client: resty.New().SetBaseURL(srv.URL)}
+
+	entry, err := c.LatestFiredAlert(context.Background(), "/services/alerts")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, int64(1000), entry.Content.TriggerTime)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "1", requests[0].URL.Query().Get("count"))
+	assert.Equal(t, "desc", requests[0].URL.Query().Get("sort_dir"))
+
+	entry, err = c.LatestFiredAlert(context.Background(), "/services/alerts/never")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+}
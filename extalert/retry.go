@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"github.com/go-resty/resty/v2"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// retryableStatusCodes are returned by a busy or restarting Splunk. The request was not processed and may succeed later.
+var retryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// useRetries retries transient failures with jittered exponential backoff between waitTime and maxWaitTime, unless
+// Splunk asks to retry after a specific time.
+func useRetries(client *resty.Client, retries int, waitTime, maxWaitTime time.Duration) {
+	client.SetRetryCount(retries)
+	client.SetRetryWaitTime(waitTime)
+	client.SetRetryMaxWaitTime(maxWaitTime)
+	client.SetRetryAfter(retryAfter)
+	client.AddRetryCondition(func(res *resty.Response, err error) bool {
+		// other retry conditions may raise the retry count of the client, e.g. to renew an expired session
+		if res == nil || res.Request.Attempt > retries {
+			return false
+		}
+		if err != nil {
+			return isRetryableError(res.Request.Method, err)
+		}
+		return slices.Contains(retryableStatusCodes, res.StatusCode())
+	})
+	client.AddRetryHook(func(res *resty.Response, err error) {
+		if res == nil || res.Request.Attempt > client.RetryCount {
+			return
+		}
+		log.Debug().Err(err).Int("status", res.StatusCode()).Int("attempt", res.Request.Attempt).Str("url", res.Request.URL).Msg("Retrying Splunk request")
+		// Parsed responses are closed already, streamed ones are discarded by the retry.
+		if res.RawResponse != nil {
+			_ = res.RawResponse.Body.Close()
+		}
+	})
+}
+
+// isRetryableError tells whether a request failing without response may succeed later. Certificate errors won't go
+// away by retrying. Other requests than GET are not retried, as Splunk may have processed them, e.g. dispatched a
+// search, before the connection failed.
+func isRetryableError(method string, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var certificateErr *tls.CertificateVerificationError
+	var recordHeaderErr tls.RecordHeaderError
+	if errors.As(err, &certificateErr) || errors.As(err, &recordHeaderErr) {
+		return false
+	}
+	return method == http.MethodGet
+}
+
+// retryAfter honors the Retry-After header of rate limited and unavailable responses. Zero falls back to the backoff.
+func retryAfter(_ *resty.Client, res *resty.Response) (time.Duration, error) {
+	if res.StatusCode() != http.StatusTooManyRequests && res.StatusCode() != http.StatusServiceUnavailable {
+		return 0, nil
+	}
+	return parseRetryAfter(res.Header().Get("Retry-After"), time.Now()), nil
+}
+
+// parseRetryAfter parses the delay in seconds or the HTTP date of a Retry-After header.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/steadybit/extension-splunk-platform/config"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func useRetryConfig(t *testing.T, retries int) {
+	previous := config.Config
+	config.Config.MaxRetries = retries
+	config.Config.RetryWaitTime = time.Millisecond
+	config.Config.RetryMaxWaitTime = 10 * time.Millisecond
+	t.Cleanup(func() { config.Config = previous })
+}
+
+// newFlakySplunkServer answers with the given status codes before succeeding. The response also serves logins.
+func newFlakySplunkServer(requests *atomic.Int32, statusCodes ...int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		attempt := int(requests.Add(1))
+		if attempt <= len(statusCodes) && statusCodes[attempt-1] != http.StatusOK {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(statusCodes[attempt-1])
+			_, _ = w.Write([]byte(`{"messages":[{"type":"ERROR","text":"Search head is busy"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"sessionKey":"key","paging":{"total":1},"entry":[{"name":"alert"}]}`))
+	}))
+}
+
+func TestRetry_RetriesTransientStatusCodes(t *testing.T) {
+	useRetryConfig(t, 3)
+	var requests atomic.Int32
+	srv := newFlakySplunkServer(&requests, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusBadGateway)
+	defer srv.Close()
+
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AccessToken: "token"})
+	require.NoError(t, err)
+	entries, err := c.Alerts(t.Context())
+
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, int32(4), requests.Load())
+}
+
+func TestRetry_GivesUpAfterMaxRetries(t *testing.T) {
+	useRetryConfig(t, 2)
+	var requests atomic.Int32
+	srv := newFlakySplunkServer(&requests, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AccessToken: "token"})
+	require.NoError(t, err)
+	_, err = c.Alerts(t.Context())
+
+	require.ErrorContains(t, err, "unexpected status code 503")
+	require.Equal(t, int32(3), requests.Load())
+}
+
+func TestRetry_DoesNotRetryFatalStatusCodes(t *testing.T) {
+	useRetryConfig(t, 3)
+	var requests atomic.Int32
+	srv := newFlakySplunkServer(&requests, http.StatusBadRequest)
+	defer srv.Close()
+
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AccessToken: "token"})
+	require.NoError(t, err)
+	_, err = c.Alerts(t.Context())
+
+	require.ErrorContains(t, err, "unexpected status code 400")
+	require.Equal(t, int32(1), requests.Load())
+}
+
+func TestRetry_DisabledDespiteSessionRetry(t *testing.T) {
+	useRetryConfig(t, 0)
+	var requests atomic.Int32
+	srv := newFlakySplunkServer(&requests, http.StatusOK, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AuthMode: config.AuthModeBasic, Username: "admin", Password: "changeme"})
+	require.NoError(t, err)
+	_, err = c.SearchJob(t.Context(), "sid")
+
+	require.ErrorContains(t, err, "unexpected status code 503")
+	require.Equal(t, int32(2), requests.Load(), "one login and one search job request")
+}
+
+func TestRetry_RetriesLogin(t *testing.T) {
+	useRetryConfig(t, 1)
+	var requests atomic.Int32
+	srv := newFlakySplunkServer(&requests, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AuthMode: config.AuthModeBasic, Username: "admin", Password: "changeme"})
+	require.NoError(t, err)
+	_, err = c.SearchJob(t.Context(), "sid")
+
+	require.NoError(t, err)
+	require.Equal(t, int32(3), requests.Load(), "two login and one search job request")
+}
+
+func TestIsRetryableError(t *testing.T) {
+	connectionRefused := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+
+	require.True(t, isRetryableError(http.MethodGet, connectionRefused))
+	require.False(t, isRetryableError(http.MethodPost, connectionRefused))
+	require.False(t, isRetryableError(http.MethodGet, fmt.Errorf("wrapped: %w", context.DeadlineExceeded)))
+	require.False(t, isRetryableError(http.MethodGet, &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	require.Equal(t, 120*time.Second, parseRetryAfter("120", now))
+	require.Equal(t, 30*time.Second, parseRetryAfter("Wed, 01 Jan 2025 10:00:30 GMT", now))
+	require.Equal(t, time.Duration(0), parseRetryAfter("Wed, 01 Jan 2025 09:00:00 GMT", now))
+	require.Equal(t, time.Duration(0), parseRetryAfter("", now))
+}
@@ -0,0 +1,318 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SavedSearchDispatchClient interface {
+	PreflightClient
+	SearchCancelClient
+	DispatchSavedSearch(ctx context.Context, dispatchUrl string, earliest, latest time.Time) (string, error)
+	SearchJob(ctx context.Context, sid string) (*SearchJobContent, error)
+}
+
+type SavedSearchCheckAction struct {
+	Clients ClientResolver[SavedSearchDispatchClient]
+}
+
+var (
+	_ action_kit_sdk.Action[SavedSearchCheckState]           = (*SavedSearchCheckAction)(nil)
+	_ action_kit_sdk.ActionWithStatus[SavedSearchCheckState] = (*SavedSearchCheckAction)(nil)
+	_ action_kit_sdk.ActionWithStop[SavedSearchCheckState]   = (*SavedSearchCheckAction)(nil)
+)
+
+type SavedSearchCheckState struct {
+	Instance    string
+	Name        string
+	DispatchUrl string
+	TimeRange   string
+	Operator    string
+	Threshold   float64
+	Start       time.Time
+	End         time.Time
+	// Sid is the search job of the saved search, empty until it was dispatched.
+	Sid string
+	// Evaluated is set once the results of the search job were checked.
+	Evaluated bool
+}
+
+const (
+	// timeRangeSavedSearch keeps the time range configured in the saved search and dispatches it at the start of the step.
+	timeRangeSavedSearch = "savedSearch"
+	// timeRangeStep covers the step and dispatches the saved search at its end.
+	timeRangeStep = "step"
+)
+
+func NewSavedSearchCheckAction(clients ClientResolver[SavedSearchDispatchClient]) action_kit_sdk.Action[SavedSearchCheckState] {
+	return &SavedSearchCheckAction{
+		Clients: clients,
+	}
+}
+
+func (a *SavedSearchCheckAction) NewEmptyState() SavedSearchCheckState {
+	return SavedSearchCheckState{}
+}
+
+func (a *SavedSearchCheckAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.check", SavedSearchTargetType),
+		Label:       "Saved Search Check",
+		Description: "Run a saved search and check the number of its results.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        new(searchIcon),
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType:          SavedSearchTargetType,
+			QuantityRestriction: extutil.Ptr(action_kit_api.ExactlyOne),
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label:       "Saved search name",
+					Description: new("Find saved search by name"),
+					Query:       attributeSavedSearchName + "=\"\"",
+				},
+			}),
+		}),
+		Technology:  new("Splunk"),
+		Category:    new("Monitoring"),
+		Kind:        action_kit_api.Check,
+		TimeControl: action_kit_api.TimeControlInternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Description:  new("The minimum duration of the step. If the search covers the step, this is the time it searches."),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("30s"),
+				Required:     new(true),
+			},
+			{
+				Name:         "timeRange",
+				Label:        "Time Range",
+				Description:  new("Either run the saved search right away with its own time range, or run it at the end of the step covering the step."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new(timeRangeStep),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Step",
+						Value: timeRangeStep,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Saved search",
+						Value: timeRangeSavedSearch,
+					},
+				}),
+				Required: new(true),
+			},
+			{
+				Name:         "operator",
+				Label:        "Operator",
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new(operatorGreaterThan),
+				Options:      new(operatorOptions()),
+				Required:     new(true),
+			},
+			{
+				Name:         "threshold",
+				Label:        "Result Count",
+				Description:  new("The number of results is compared to this value."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new("0"),
+				Required:     new(true),
+			},
+		},
+		Widgets: new([]action_kit_api.Widget{
+			action_kit_api.StateOverTimeWidget{
+				Type:  action_kit_api.ComSteadybitWidgetStateOverTime,
+				Title: "Saved Search Result",
+				Identity: action_kit_api.StateOverTimeWidgetIdentityConfig{
+					From: searchMetricId,
+				},
+				Label: action_kit_api.StateOverTimeWidgetLabelConfig{
+					From: searchMetricLabel,
+				},
+				State: action_kit_api.StateOverTimeWidgetStateConfig{
+					From: searchMetricState,
+				},
+				Tooltip: action_kit_api.StateOverTimeWidgetTooltipConfig{
+					From: searchMetricTooltip,
+				},
+				Value: new(action_kit_api.StateOverTimeWidgetValueConfig{
+					Hide: new(true),
+				}),
+			},
+		}),
+		Status: new(action_kit_api.MutatingEndpointReferenceWithCallInterval{
+			CallInterval: new("1s"),
+		}),
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *SavedSearchCheckAction) Prepare(ctx context.Context, state *SavedSearchCheckState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	name := request.Target.Attributes[attributeSavedSearchName]
+	if len(name) == 0 {
+		return nil, fmt.Errorf("target is missing the name attribute")
+	}
+	dispatchUrl := request.Target.Attributes[attributeSavedSearchDispatchUrl]
+	if len(dispatchUrl) == 0 {
+		return nil, fmt.Errorf("target is missing the dispatch url attribute")
+	}
+
+	timeRange := extutil.ToString(request.Config["timeRange"])
+	switch timeRange {
+	case timeRangeStep, timeRangeSavedSearch:
+	default:
+		return nil, fmt.Errorf("unsupported time range %q", timeRange)
+	}
+
+	operator := extutil.ToString(request.Config["operator"])
+	switch operator {
+	case operatorGreaterThan, operatorGreaterThanOrEqual, operatorLessThan, operatorLessThanOrEqual, operatorEqual, operatorNotEqual:
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", operator)
+	}
+
+	threshold, err := strconv.ParseFloat(strings.TrimSpace(extutil.ToString(request.Config["threshold"])), 64)
+	if err != nil {
+		return nil, fmt.Errorf("threshold parameter is not a number: %w", err)
+	}
+
+	start := time.Now()
+	end := start.Add(time.Duration(extutil.ToInt64(request.Config["duration"])) * time.Millisecond)
+
+	if instance := request.Target.Attributes[attributeInstance]; len(instance) > 0 {
+		state.Instance = instance[0]
+	}
+	state.Name = name[0]
+	state.DispatchUrl = dispatchUrl[0]
+	state.TimeRange = timeRange
+	state.Operator = operator
+	state.Threshold = threshold
+	state.Start = start
+	state.End = end
+
+	if err := preflight(ctx, a.Clients, state.Instance, capabilityListSavedSearches, capabilitySearch); err != nil {
+		return nil, err
+	}
+
+	log.Trace().Any("state", state).Msg("saved search check action state")
+
+	return nil, nil
+}
+
+func (a *SavedSearchCheckAction) Start(ctx context.Context, state *SavedSearchCheckState) (*action_kit_api.StartResult, error) {
+	client, err := a.Clients(state.Instance)
+	if err != nil {
+		return nil, err
+	}
+	statusResult, err := checkSavedSearch(ctx, state, client)
+	if statusResult == nil {
+		return nil, toExtensionError(err)
+	}
+	return &action_kit_api.StartResult{
+		Error:   statusResult.Error,
+		Metrics: statusResult.Metrics,
+	}, toExtensionError(err)
+}
+
+func (a *SavedSearchCheckAction) Status(ctx context.Context, state *SavedSearchCheckState) (*action_kit_api.StatusResult, error) {
+	client, err := a.Clients(state.Instance)
+	if err != nil {
+		return nil, err
+	}
+	statusResult, err := checkSavedSearch(ctx, state, client)
+	return statusResult, toExtensionError(err)
+}
+
+// Stop cancels the search job of the saved search if it is still running, so an aborted check doesn't leave it running
+// in Splunk.
+func (a *SavedSearchCheckAction) Stop(ctx context.Context, state *SavedSearchCheckState) (*action_kit_api.StopResult, error) {
+	if !state.Evaluated {
+		cancelSearch(ctx, a.Clients, state.Instance, &state.Sid)
+	}
+	return nil, nil
+}
+
+// checkSavedSearch dispatches the saved search once, waits for its job and checks the result count. The check
+// completes once the result was checked and the step duration has passed.
+func checkSavedSearch(ctx context.Context, state *SavedSearchCheckState, client SavedSearchDispatchClient) (*action_kit_api.StatusResult, error) {
+	now := time.Now()
+
+	if state.Evaluated {
+		return &action_kit_api.StatusResult{Completed: now.After(state.End)}, nil
+	}
+
+	if state.Sid == "" {
+		var earliest, latest time.Time
+		if state.TimeRange == timeRangeStep {
+			if !now.After(state.End) {
+				return &action_kit_api.StatusResult{Completed: false}, nil
+			}
+			earliest, latest = state.Start, state.End
+		}
+		sid, err := client.DispatchSavedSearch(ctx, state.DispatchUrl, earliest, latest)
+		if err != nil {
+			return nil, err
+		}
+		state.Sid = sid
+		return &action_kit_api.StatusResult{Completed: false}, nil
+	}
+
+	job, err := client.SearchJob(ctx, state.Sid)
+	if err != nil {
+		return nil, err
+	}
+	if job.IsFailed || job.DispatchState == "FAILED" {
+		return nil, fmt.Errorf("search job %s of saved search %q failed", state.Sid, state.Name)
+	}
+	if !job.IsDone {
+		return &action_kit_api.StatusResult{Completed: false}, nil
+	}
+	state.Evaluated = true
+
+	resultCount := float64(job.ResultCount)
+	matches := compare(resultCount, state.Operator, state.Threshold)
+
+	var checkError *action_kit_api.ActionKitError
+	if !matches {
+		checkError = new(action_kit_api.ActionKitError{
+			Title:  fmt.Sprintf("Saved search %q returned %d results, expected %s %s.", state.Name, job.ResultCount, state.Operator, formatValue(state.Threshold)),
+			Status: extutil.Ptr(action_kit_api.Failed),
+		})
+	}
+
+	metricState := "danger"
+	if matches {
+		metricState = "success"
+	}
+
+	return &action_kit_api.StatusResult{
+		// a failed check is final, there is no need to wait for the end of the step
+		Completed: !matches || now.After(state.End),
+		Error:     checkError,
+		Metrics: new([]action_kit_api.Metric{
+			{
+				Name: new("Splunk Saved Search"),
+				Metric: map[string]string{
+					searchMetricId:      state.Name,
+					searchMetricLabel:   fmt.Sprintf("result count %s %s", state.Operator, formatValue(state.Threshold)),
+					searchMetricState:   metricState,
+					searchMetricTooltip: fmt.Sprintf("Saved search %q returned %d results", state.Name, job.ResultCount),
+				},
+				Timestamp: now,
+				Value:     resultCount,
+			},
+		}),
+	}, nil
+}
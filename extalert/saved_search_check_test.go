@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-splunk-platform/config"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type MockSavedSearchDispatchClient struct {
+	MockSearchClient
+	// dispatched records the time range of each dispatch.
+	dispatched *[][2]time.Time
+}
+
+func (c MockSavedSearchDispatchClient) DispatchSavedSearch(_ context.Context, _ string, earliest, latest time.Time) (string, error) {
+	if c.dispatched != nil {
+		*c.dispatched = append(*c.dispatched, [2]time.Time{earliest, latest})
+	}
+	return c.sid, c.err
+}
+
+func TestSavedSearchCheckAction_Prepare(t *testing.T) {
+	action := NewSavedSearchCheckAction(resolveTo[SavedSearchDispatchClient](MockSavedSearchDispatchClient{}))
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				attributeSavedSearchName:        {"Errors"},
+				attributeSavedSearchDispatchUrl: {"/servicesNS/nobody/search/saved/searches/Errors/dispatch"},
+				attributeInstance:               {"production"},
+			},
+		},
+		Config: map[string]any{
+			"duration":  1000,
+			"timeRange": timeRangeStep,
+			"operator":  operatorEqual,
+			"threshold": "0",
+		},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "production", state.Instance)
+	require.Equal(t, "Errors", state.Name)
+	require.Equal(t, "/servicesNS/nobody/search/saved/searches/Errors/dispatch", state.DispatchUrl)
+	require.Equal(t, timeRangeStep, state.TimeRange)
+	require.Equal(t, operatorEqual, state.Operator)
+	require.Greater(t, state.End, state.Start)
+}
+
+func TestSavedSearchCheckAction_Prepare_missingDispatchUrl(t *testing.T) {
+	action := NewSavedSearchCheckAction(resolveTo[SavedSearchDispatchClient](MockSavedSearchDispatchClient{}))
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{attributeSavedSearchName: {"Errors"}},
+		},
+		Config: map[string]any{"timeRange": timeRangeStep, "operator": operatorEqual, "threshold": "0"},
+	})
+
+	require.EqualError(t, err, "target is missing the dispatch url attribute")
+}
+
+func TestCheckSavedSearch_stepTimeRange(t *testing.T) {
+	var dispatched [][2]time.Time
+	client := MockSavedSearchDispatchClient{
+		MockSearchClient: MockSearchClient{sid: "sid", job: SearchJobContent{IsDone: true, ResultCount: 0}},
+		dispatched:       &dispatched,
+	}
+	now := time.Now()
+	state := &SavedSearchCheckState{Name: "Errors", TimeRange: timeRangeStep, Operator: operatorEqual, Threshold: 0, Start: now, End: now.Add(time.Hour)}
+
+	// the search waits for the end of the step
+	result, err := checkSavedSearch(t.Context(), state, client)
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.Empty(t, dispatched)
+
+	state.End = time.Now().Add(-time.Millisecond)
+	result, err = checkSavedSearch(t.Context(), state, client)
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.Equal(t, [][2]time.Time{{state.Start, state.End}}, dispatched)
+	require.Equal(t, "sid", state.Sid)
+
+	result, err = checkSavedSearch(t.Context(), state, client)
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+	require.Nil(t, result.Error)
+	require.Equal(t, "success", (*result.Metrics)[0].Metric[searchMetricState])
+}
+
+func TestCheckSavedSearch_savedSearchTimeRangeFails(t *testing.T) {
+	var dispatched [][2]time.Time
+	client := MockSavedSearchDispatchClient{
+		MockSearchClient: MockSearchClient{sid: "sid", job: SearchJobContent{IsDone: true, ResultCount: 3}},
+		dispatched:       &dispatched,
+	}
+	now := time.Now()
+	state := &SavedSearchCheckState{Name: "Errors", TimeRange: timeRangeSavedSearch, Operator: operatorEqual, Threshold: 0, Start: now, End: now.Add(time.Hour)}
+
+	result, err := checkSavedSearch(t.Context(), state, client)
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.Equal(t, [][2]time.Time{{}}, dispatched)
+
+	result, err = checkSavedSearch(t.Context(), state, client)
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Saved search "Errors" returned 3 results, expected = 0.`, result.Error.Title)
+}
+
+func TestCheckSavedSearch_waitsForStepEndAfterSuccess(t *testing.T) {
+	client := MockSavedSearchDispatchClient{MockSearchClient: MockSearchClient{sid: "sid", job: SearchJobContent{IsDone: true, ResultCount: 1}}}
+	now := time.Now()
+	state := &SavedSearchCheckState{Name: "Errors", TimeRange: timeRangeSavedSearch, Operator: operatorGreaterThan, Threshold: 0, Start: now, End: now.Add(time.Hour)}
+
+	_, err := checkSavedSearch(t.Context(), state, client)
+	require.NoError(t, err)
+	result, err := checkSavedSearch(t.Context(), state, client)
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.True(t, state.Evaluated)
+
+	state.End = time.Now().Add(-time.Millisecond)
+	result, err = checkSavedSearch(t.Context(), state, client)
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+}
+
+func TestSavedSearchCheckAction_Stop_cancelsRunningJob(t *testing.T) {
+	var cancelled []string
+	client := MockSavedSearchDispatchClient{MockSearchClient: MockSearchClient{cancelled: &cancelled}}
+	action := NewSavedSearchCheckAction(resolveTo[SavedSearchDispatchClient](client)).(action_kit_sdk.ActionWithStop[SavedSearchCheckState])
+
+	_, err := action.Stop(t.Context(), &SavedSearchCheckState{Sid: "evaluated", Evaluated: true})
+	require.NoError(t, err)
+	require.Empty(t, cancelled)
+
+	_, err = action.Stop(t.Context(), &SavedSearchCheckState{Sid: "running"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"running"}, cancelled)
+}
+
+func TestSplunkClient_DispatchSavedSearch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/servicesNS/nobody/search/saved/searches/My Errors/dispatch", r.URL.Path)
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "0", r.PostForm.Get("trigger_actions"))
+		require.Equal(t, "120", r.PostForm.Get("dispatch.ttl"))
+		require.Equal(t, "1700000000", r.PostForm.Get("dispatch.earliest_time"))
+		require.Equal(t, "1700000060", r.PostForm.Get("dispatch.latest_time"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"admin__search__RMD5_at_1700000060"}`))
+	}))
+	defer srv.Close()
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AccessToken: "token"})
+	require.NoError(t, err)
+
+	sid, err := c.DispatchSavedSearch(t.Context(), "/servicesNS/nobody/search/saved/searches/My%20Errors/dispatch", time.Unix(1700000000, 0), time.Unix(1700000060, 0))
+
+	require.NoError(t, err)
+	require.Equal(t, "admin__search__RMD5_at_1700000060", sid)
+}
@@ -0,0 +1,236 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-splunk-platform/config"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SavedSearchClient interface {
+	SavedSearches(ctx context.Context) ([]Entry, error)
+}
+
+type savedSearchDiscovery struct {
+	Instances []string
+	Clients   ClientResolver[SavedSearchClient]
+
+	targets instanceTargets
+}
+
+var (
+	_ discovery_kit_sdk.TargetDescriber    = (*savedSearchDiscovery)(nil)
+	_ discovery_kit_sdk.AttributeDescriber = (*savedSearchDiscovery)(nil)
+)
+
+func NewSavedSearchDiscovery(instances []string, clients ClientResolver[SavedSearchClient]) discovery_kit_sdk.TargetDiscovery {
+	discovery := newSavedSearchDiscovery(instances, clients)
+	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
+		discovery_kit_sdk.WithRefreshTargetsNow(),
+		discovery_kit_sdk.WithRefreshTargetsInterval(context.Background(), 1*time.Minute),
+	)
+}
+
+func newSavedSearchDiscovery(instances []string, clients ClientResolver[SavedSearchClient]) *savedSearchDiscovery {
+	return &savedSearchDiscovery{
+		Instances: instances,
+		Clients:   clients,
+		targets:   newInstanceTargets("saved searches"),
+	}
+}
+
+func (d *savedSearchDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
+	return discovery_kit_api.DiscoveryDescription{
+		Id: SavedSearchTargetType,
+		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
+			CallInterval: new("1m"),
+		},
+	}
+}
+
+func (d *savedSearchDiscovery) DescribeTarget() discovery_kit_api.TargetDescription {
+	return discovery_kit_api.TargetDescription{
+		Id:       SavedSearchTargetType,
+		Label:    discovery_kit_api.PluralLabel{One: "Splunk Saved Search", Other: "Splunk Saved Searches"},
+		Category: new("monitoring"),
+		Version:  extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:     new(searchIcon),
+		Table: discovery_kit_api.Table{
+			Columns: []discovery_kit_api.Column{
+				{Attribute: attributeSavedSearchName},
+				{Attribute: attributeSavedSearchApp},
+				{Attribute: attributeSavedSearchIsScheduled},
+				{Attribute: attributeSavedSearchDisabled},
+			},
+			OrderBy: []discovery_kit_api.OrderBy{
+				{
+					Attribute: attributeSavedSearchName,
+					Direction: "ASC",
+				},
+			},
+		},
+	}
+}
+
+func (d *savedSearchDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDescription {
+	return []discovery_kit_api.AttributeDescription{
+		{
+			Attribute: attributeSavedSearchID,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "ID",
+				Other: "IDs",
+			},
+		},
+		{
+			Attribute: attributeSavedSearchName,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Name",
+				Other: "Names",
+			},
+		},
+		{
+			Attribute: attributeSavedSearchApp,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "App",
+				Other: "Apps",
+			},
+		},
+		{
+			Attribute: attributeSavedSearchOwner,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Owner",
+				Other: "Owners",
+			},
+		},
+		{
+			Attribute: attributeSavedSearchSharing,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Sharing",
+				Other: "Sharings",
+			},
+		},
+		{
+			Attribute: attributeSavedSearchCronSchedule,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Cron Schedule",
+				Other: "Cron Schedules",
+			},
+		},
+		{
+			Attribute: attributeSavedSearchIsScheduled,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Scheduled",
+				Other: "Scheduled",
+			},
+		},
+		{
+			Attribute: attributeSavedSearchDisabled,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Disabled",
+				Other: "Disabled",
+			},
+		},
+		{
+			Attribute: attributeSavedSearchDispatchUrl,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Dispatch Url",
+				Other: "Dispatch Urls",
+			},
+		},
+		{
+			Attribute: attributeSavedSearchAlertType,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Alert Type",
+				Other: "Alert Types",
+			},
+		},
+		{
+			Attribute: attributeSavedSearchActions,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Alert Action",
+				Other: "Alert Actions",
+			},
+		},
+	}
+}
+
+func (d *savedSearchDiscovery) DiscoverTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	return d.getAllSavedSearchTargets(ctx)
+}
+
+func (d *savedSearchDiscovery) getAllSavedSearchTargets(ctx context.Context) ([]discovery_kit_api.Target, error) {
+	result, err := d.targets.discover(ctx, d.Instances, d.getSavedSearchTargets)
+	if err != nil {
+		return result, err
+	}
+	return discovery_kit_commons.ApplyAttributeExcludes(result, config.Config.DiscoveryAttributesExcludesSavedSearch), nil
+}
+
+func (d *savedSearchDiscovery) getSavedSearchTargets(ctx context.Context, instance string) ([]discovery_kit_api.Target, error) {
+	client, err := d.Clients(instance)
+	if err != nil {
+		return nil, err
+	}
+	savedSearches, err := client.SavedSearches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]discovery_kit_api.Target, 0, len(savedSearches))
+	for _, savedSearch := range savedSearches {
+		attributes := map[string][]string{
+			attributeSavedSearchID:          {savedSearch.Id},
+			attributeSavedSearchName:        {savedSearch.Name},
+			attributeSavedSearchApp:         {savedSearch.ACL.App},
+			attributeSavedSearchOwner:       {savedSearch.ACL.Owner},
+			attributeSavedSearchSharing:     {savedSearch.ACL.Sharing},
+			attributeSavedSearchIsScheduled: {strconv.FormatBool(bool(savedSearch.Content.IsScheduled))},
+			attributeSavedSearchDisabled:    {strconv.FormatBool(bool(savedSearch.Content.Disabled))},
+			attributeSavedSearchDispatchUrl: {dispatchUrl(savedSearch)},
+			attributeInstance:               {instance},
+		}
+		if savedSearch.Content.CronSchedule != "" {
+			attributes[attributeSavedSearchCronSchedule] = []string{savedSearch.Content.CronSchedule}
+		}
+		if savedSearch.Content.AlertType != "" {
+			attributes[attributeSavedSearchAlertType] = []string{savedSearch.Content.AlertType}
+		}
+		if actions := splitActions(savedSearch.Content.Actions); len(actions) > 0 {
+			attributes[attributeSavedSearchActions] = actions
+		}
+		result = append(result, discovery_kit_api.Target{
+			Id:         savedSearch.Id,
+			TargetType: SavedSearchTargetType,
+			Label:      savedSearch.Name,
+			Attributes: attributes,
+		})
+	}
+	return result, nil
+}
+
+// dispatchUrl returns the path to run the saved search, falling back to the global namespace if Splunk didn't link it.
+func dispatchUrl(savedSearch Entry) string {
+	if savedSearch.Links.Dispatch != "" {
+		return savedSearch.Links.Dispatch
+	}
+	return "/services/saved/searches/" + url.PathEscape(savedSearch.Name) + "/dispatch"
+}
+
+func splitActions(actions string) []string {
+	var result []string
+	for _, action := range strings.Split(actions, ",") {
+		if action = strings.TrimSpace(action); action != "" {
+			result = append(result, action)
+		}
+	}
+	return result
+}
@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/steadybit/extension-splunk-platform/config"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSavedSearchDiscovery_DiscoverTargets(t *testing.T) {
+	discovery := newSavedSearchDiscovery([]string{"default"}, resolveTo[SavedSearchClient](MockSplunkClient{
+		response: []Entry{
+			{
+				Id:    "https://localhost:8089/servicesNS/nobody/search/saved/searches/Errors",
+				Name:  "Errors",
+				ACL:   ACL{App: "search", Owner: "nobody", Sharing: "app"},
+				Links: Links{Dispatch: "/servicesNS/nobody/search/saved/searches/Errors/dispatch"},
+				Content: Content{
+					CronSchedule: "*/5 * * * *",
+					IsScheduled:  true,
+					AlertType:    "number of events",
+					Actions:      "email, webhook",
+				},
+			},
+			{
+				Id:      "https://localhost:8089/servicesNS/admin/search/saved/searches/Report",
+				Name:    "Report",
+				ACL:     ACL{App: "search", Owner: "admin", Sharing: "user"},
+				Content: Content{Disabled: true},
+			},
+		},
+	}))
+
+	targets, err := discovery.getAllSavedSearchTargets(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+
+	require.Equal(t, SavedSearchTargetType, targets[0].TargetType)
+	require.Equal(t, "Errors", targets[0].Label)
+	require.Equal(t, map[string][]string{
+		attributeSavedSearchID:           {"https://localhost:8089/servicesNS/nobody/search/saved/searches/Errors"},
+		attributeSavedSearchName:         {"Errors"},
+		attributeSavedSearchApp:          {"search"},
+		attributeSavedSearchOwner:        {"nobody"},
+		attributeSavedSearchSharing:      {"app"},
+		attributeSavedSearchCronSchedule: {"*/5 * * * *"},
+		attributeSavedSearchIsScheduled:  {"true"},
+		attributeSavedSearchDisabled:     {"false"},
+		attributeSavedSearchDispatchUrl:  {"/servicesNS/nobody/search/saved/searches/Errors/dispatch"},
+		attributeSavedSearchAlertType:    {"number of events"},
+		attributeSavedSearchActions:      {"email", "webhook"},
+		attributeInstance:                {"default"},
+	}, targets[0].Attributes)
+
+	require.Equal(t, "Report", targets[1].Label)
+	require.Equal(t, []string{"true"}, targets[1].Attributes[attributeSavedSearchDisabled])
+	require.NotContains(t, targets[1].Attributes, attributeSavedSearchCronSchedule)
+	require.NotContains(t, targets[1].Attributes, attributeSavedSearchActions)
+	require.Equal(t, []string{"/services/saved/searches/Report/dispatch"}, targets[1].Attributes[attributeSavedSearchDispatchUrl])
+}
+
+func TestSavedSearchDiscovery_DiscoverTargets_excludedAttributes(t *testing.T) {
+	config.Config.DiscoveryAttributesExcludesSavedSearch = []string{attributeSavedSearchOwner}
+	defer func() {
+		config.Config.DiscoveryAttributesExcludesSavedSearch = []string{}
+	}()
+
+	discovery := newSavedSearchDiscovery([]string{"default"}, resolveTo[SavedSearchClient](MockSplunkClient{
+		response: []Entry{{Id: "search1", Name: "Search One", ACL: ACL{Owner: "admin"}}},
+	}))
+
+	targets, err := discovery.getAllSavedSearchTargets(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	require.NotContains(t, targets[0].Attributes, attributeSavedSearchOwner)
+}
+
+func TestSavedSearchDiscovery_DiscoverTargets_errorResponse(t *testing.T) {
+	discovery := newSavedSearchDiscovery([]string{"default"}, resolveTo[SavedSearchClient](MockSplunkClient{
+		err: fmt.Errorf("some error"),
+	}))
+
+	targets, err := discovery.getAllSavedSearchTargets(context.Background())
+
+	require.Empty(t, targets)
+	require.Error(t, err)
+}
+
+func TestSplunkClient_SavedSearches(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/services/saved/searches", r.URL.Path)
+		require.Empty(t, r.URL.Query().Get("search"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"entry":[{"name":"Report","acl":{"app":"search","owner":"admin","sharing":"global"},"content":{"is_scheduled":"1","disabled":false,"cron_schedule":"0 6 * * *"}}]}`))
+	}))
+	defer srv.Close()
+	c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AccessToken: "token"})
+	require.NoError(t, err)
+
+	savedSearches, err := c.SavedSearches(t.Context())
+
+	require.NoError(t, err)
+	require.Len(t, savedSearches, 1)
+	require.Equal(t, ACL{App: "search", Owner: "admin", Sharing: "global"}, savedSearches[0].ACL)
+	require.True(t, bool(savedSearches[0].Content.IsScheduled))
+	require.False(t, bool(savedSearches[0].Content.Disabled))
+}
+
+func TestFlag_UnmarshalJSON(t *testing.T) {
+	for raw, expected := range map[string]bool{`true`: true, `false`: false, `1`: true, `0`: false, `"1"`: true, `"false"`: false, `""`: false} {
+		var flag Flag
+		require.NoError(t, json.Unmarshal([]byte(raw), &flag), raw)
+		require.Equal(t, expected, bool(flag), raw)
+	}
+}
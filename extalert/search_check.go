@@ -0,0 +1,533 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SearchClient interface {
+	PreflightClient
+	SearchCancelClient
+	DispatchSearch(ctx context.Context, query string, earliest, latest time.Time) (string, error)
+	SearchJob(ctx context.Context, sid string) (*SearchJobContent, error)
+	SearchResults(ctx context.Context, sid string) ([]map[string]any, error)
+}
+
+type SearchCancelClient interface {
+	CancelSearch(ctx context.Context, sid string) error
+}
+
+type SearchCheckAction struct {
+	Clients ClientResolver[SearchClient]
+}
+
+var (
+	_ action_kit_sdk.Action[SearchCheckState]           = (*SearchCheckAction)(nil)
+	_ action_kit_sdk.ActionWithStatus[SearchCheckState] = (*SearchCheckAction)(nil)
+	_ action_kit_sdk.ActionWithStop[SearchCheckState]   = (*SearchCheckAction)(nil)
+)
+
+type SearchCheckState struct {
+	Instance          string
+	Query             string
+	Field             string
+	Operator          string
+	Threshold         float64
+	Start             time.Time
+	End               time.Time
+	StateCheckMode    string
+	StateCheckSuccess bool
+	FailEarly         bool
+	// DeviationTitle remembers the first observed deviation in 'All the time' + fail-at-end mode
+	// (FailEarly = false) so it can be reported once the step ends.
+	DeviationTitle string
+	// Sid is the search job currently in flight, empty if no job is running.
+	Sid string
+	// SearchLatest is the latest time covered by the search job in flight.
+	SearchLatest time.Time
+	// SeriesEmittedUntil is the time of the latest timechart bucket already emitted as metric.
+	SeriesEmittedUntil time.Time
+}
+
+const (
+	operatorGreaterThan        = ">"
+	operatorGreaterThanOrEqual = ">="
+	operatorLessThan           = "<"
+	operatorLessThanOrEqual    = "<="
+	operatorEqual              = "="
+	operatorNotEqual           = "!="
+)
+
+func NewSearchCheckAction(clients ClientResolver[SearchClient]) action_kit_sdk.Action[SearchCheckState] {
+	return &SearchCheckAction{
+		Clients: clients,
+	}
+}
+
+func (a *SearchCheckAction) NewEmptyState() SearchCheckState {
+	return SearchCheckState{}
+}
+
+func (a *SearchCheckAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.check", searchType),
+		Label:       "Search Check",
+		Description: "Run an SPL query over the experiment window and check its result.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        new(searchIcon),
+		Technology:  new("Splunk"),
+		Category:    new("Monitoring"),
+		Kind:        action_kit_api.Check,
+		TimeControl: action_kit_api.TimeControlInternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("30s"),
+				Required:     new(true),
+			},
+			{
+				Name:        "query",
+				Label:       "SPL Query",
+				Description: new("The search to run, for example 'index=main status>=500 | stats count'. The search covers the time from the start of the step until now."),
+				Type:        action_kit_api.ActionParameterTypeTextarea,
+				Required:    new(true),
+			},
+			{
+				Name:        "field",
+				Label:       "Field",
+				Description: new("Numeric field of the first result row to check, for example 'count' or 'avg(latency)'. If empty, the number of results is checked."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    new(false),
+			},
+			{
+				Name:         "operator",
+				Label:        "Operator",
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new(operatorGreaterThan),
+				Options:      new(operatorOptions()),
+				Required:     new(true),
+			},
+			{
+				Name:         "threshold",
+				Label:        "Threshold",
+				Description:  new("The value the result is compared to."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new("0"),
+				Required:     new(true),
+			},
+			{
+				Name:         "stateCheckMode",
+				Label:        "State Check Mode",
+				Description:  new("How often should the search result match the expectation?"),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new(stateCheckModeAllTheTime),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "All the time",
+						Value: stateCheckModeAllTheTime,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "At least once",
+						Value: stateCheckModeAtLeastOnce,
+					},
+				}),
+				Required: new(true),
+			},
+			{
+				Name:         "failEarly",
+				Label:        "Fail early",
+				Description:  new("If enabled, the check fails as soon as a deviating result is observed. If disabled, the check keeps searching for the whole duration and only fails at the end of the step. Only affects the 'All the time' mode."),
+				Type:         action_kit_api.ActionParameterTypeBoolean,
+				DefaultValue: new("true"),
+				Advanced:     new(true),
+				Required:     new(false),
+			},
+			instanceParameter(),
+		},
+		Widgets: new([]action_kit_api.Widget{
+			action_kit_api.StateOverTimeWidget{
+				Type:  action_kit_api.ComSteadybitWidgetStateOverTime,
+				Title: "Search Result",
+				Identity: action_kit_api.StateOverTimeWidgetIdentityConfig{
+					From: searchMetricId,
+				},
+				Label: action_kit_api.StateOverTimeWidgetLabelConfig{
+					From: searchMetricLabel,
+				},
+				State: action_kit_api.StateOverTimeWidgetStateConfig{
+					From: searchMetricState,
+				},
+				Tooltip: action_kit_api.StateOverTimeWidgetTooltipConfig{
+					From: searchMetricTooltip,
+				},
+				Value: new(action_kit_api.StateOverTimeWidgetValueConfig{
+					Hide: new(true),
+				}),
+			},
+			action_kit_api.LineChartWidget{
+				Type:  action_kit_api.ComSteadybitWidgetLineChart,
+				Title: "Search Values",
+				Identity: action_kit_api.LineChartWidgetIdentityConfig{
+					MetricName: searchSeriesMetricName,
+					From:       searchMetricSeries,
+					Mode:       action_kit_api.ComSteadybitWidgetLineChartIdentityModeWidgetPerValue,
+				},
+				Tooltip: new(action_kit_api.LineChartWidgetTooltipConfig{
+					MetricValueTitle: new("Value"),
+				}),
+			},
+		}),
+		Status: new(action_kit_api.MutatingEndpointReferenceWithCallInterval{
+			CallInterval: new("1s"),
+		}),
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func operatorOptions() []action_kit_api.ParameterOption {
+	var options []action_kit_api.ParameterOption
+	for _, operator := range []string{operatorGreaterThan, operatorGreaterThanOrEqual, operatorLessThan, operatorLessThanOrEqual, operatorEqual, operatorNotEqual} {
+		options = append(options, action_kit_api.ExplicitParameterOption{
+			Label: operator,
+			Value: operator,
+		})
+	}
+	return options
+}
+
+func (a *SearchCheckAction) Prepare(ctx context.Context, state *SearchCheckState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	query := strings.TrimSpace(extutil.ToString(request.Config["query"]))
+	if query == "" {
+		return nil, fmt.Errorf("query parameter is missing")
+	}
+
+	operator := extutil.ToString(request.Config["operator"])
+	switch operator {
+	case operatorGreaterThan, operatorGreaterThanOrEqual, operatorLessThan, operatorLessThanOrEqual, operatorEqual, operatorNotEqual:
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", operator)
+	}
+
+	threshold, err := strconv.ParseFloat(strings.TrimSpace(extutil.ToString(request.Config["threshold"])), 64)
+	if err != nil {
+		return nil, fmt.Errorf("threshold parameter is not a number: %w", err)
+	}
+
+	stateCheckMode := extutil.ToString(request.Config["stateCheckMode"])
+	if stateCheckMode == "" {
+		return nil, fmt.Errorf("expected state check mode parameter is missing")
+	}
+
+	start := time.Now()
+	end := start.Add(time.Duration(extutil.ToInt64(request.Config["duration"])) * time.Millisecond)
+
+	state.Instance = extutil.ToString(request.Config["instance"])
+	state.Query = query
+	state.Field = strings.TrimSpace(extutil.ToString(request.Config["field"]))
+	state.Operator = operator
+	state.Threshold = threshold
+	state.Start = start
+	state.End = end
+	state.StateCheckMode = stateCheckMode
+	state.FailEarly = true
+	if request.Config["failEarly"] != nil {
+		state.FailEarly = extutil.ToBool(request.Config["failEarly"])
+	}
+
+	if err := preflight(ctx, a.Clients, state.Instance, capabilitySearch); err != nil {
+		return nil, err
+	}
+
+	log.Trace().Any("state", state).Msg("search check action state")
+
+	return nil, nil
+}
+
+func (a *SearchCheckAction) Start(ctx context.Context, state *SearchCheckState) (*action_kit_api.StartResult, error) {
+	client, err := a.Clients(state.Instance)
+	if err != nil {
+		return nil, err
+	}
+	statusResult, err := checkSearch(ctx, state, client)
+	if statusResult == nil {
+		return nil, toExtensionError(err)
+	}
+	return &action_kit_api.StartResult{
+		Artifacts: statusResult.Artifacts,
+		Error:     statusResult.Error,
+		Messages:  statusResult.Messages,
+		Metrics:   statusResult.Metrics,
+	}, toExtensionError(err)
+}
+
+func (a *SearchCheckAction) Status(ctx context.Context, state *SearchCheckState) (*action_kit_api.StatusResult, error) {
+	client, err := a.Clients(state.Instance)
+	if err != nil {
+		return nil, err
+	}
+	statusResult, err := checkSearch(ctx, state, client)
+	return statusResult, toExtensionError(err)
+}
+
+// Stop cancels the search job in flight, so an aborted check doesn't leave it running in Splunk.
+func (a *SearchCheckAction) Stop(ctx context.Context, state *SearchCheckState) (*action_kit_api.StopResult, error) {
+	cancelSearch(ctx, a.Clients, state.Instance, &state.Sid)
+	return nil, nil
+}
+
+// cancelSearch cancels the search job in flight, if any. Failures are only logged, as the job expires on its own.
+func cancelSearch[T SearchCancelClient](ctx context.Context, clients ClientResolver[T], instance string, sid *string) {
+	if *sid == "" {
+		return
+	}
+	client, err := clients(instance)
+	if err == nil {
+		err = client.CancelSearch(ctx, *sid)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("instance", instance).Str("sid", *sid).Msg("Failed to cancel search job")
+		return
+	}
+	*sid = ""
+}
+
+// checkSearch runs one search job after the other, each covering the time from the start of the step until the job
+// was dispatched. The check completes once a job covering the whole step has been evaluated.
+func checkSearch(ctx context.Context, state *SearchCheckState, client SearchClient) (*action_kit_api.StatusResult, error) {
+	now := time.Now()
+
+	if state.Sid == "" {
+		sid, err := client.DispatchSearch(ctx, state.Query, state.Start, now)
+		if err != nil {
+			return nil, err
+		}
+		state.Sid = sid
+		state.SearchLatest = now
+		return &action_kit_api.StatusResult{Completed: false}, nil
+	}
+
+	job, err := client.SearchJob(ctx, state.Sid)
+	if err != nil {
+		return nil, err
+	}
+	if job.IsFailed || job.DispatchState == "FAILED" {
+		return nil, fmt.Errorf("search job %s failed", state.Sid)
+	}
+	if !job.IsDone {
+		return &action_kit_api.StatusResult{Completed: false}, nil
+	}
+
+	results, err := client.SearchResults(ctx, state.Sid)
+	if err != nil {
+		return nil, err
+	}
+	state.Sid = ""
+
+	completed := state.SearchLatest.After(state.End)
+	value, found := searchValue(state, job, results)
+	matches := found && compare(value, state.Operator, state.Threshold)
+
+	var checkError *action_kit_api.ActionKitError
+	if state.StateCheckMode == stateCheckModeAllTheTime {
+		if !matches {
+			title := searchDeviationTitle(state, value, found)
+			if state.FailEarly {
+				checkError = new(action_kit_api.ActionKitError{
+					Title:  title,
+					Status: extutil.Ptr(action_kit_api.Failed),
+				})
+			} else if state.DeviationTitle == "" {
+				state.DeviationTitle = title
+			}
+		}
+		if !state.FailEarly && completed && state.DeviationTitle != "" {
+			checkError = new(action_kit_api.ActionKitError{
+				Title:  state.DeviationTitle,
+				Status: extutil.Ptr(action_kit_api.Failed),
+			})
+		}
+	} else if state.StateCheckMode == stateCheckModeAtLeastOnce {
+		if matches {
+			state.StateCheckSuccess = true
+		}
+		if completed && !state.StateCheckSuccess {
+			checkError = new(action_kit_api.ActionKitError{
+				Title:  fmt.Sprintf("Search result never matched %s %s.", state.Operator, formatValue(state.Threshold)),
+				Status: extutil.Ptr(action_kit_api.Failed),
+			})
+		}
+	}
+
+	return &action_kit_api.StatusResult{
+		Completed: completed,
+		Error:     checkError,
+		Metrics:   new(append(toSearchMetrics(state, value, found, matches, now), toSeriesMetrics(state, results, completed, now)...)),
+	}, nil
+}
+
+// searchValue returns the value to check for a finished job: the result count if no field is configured, the
+// numeric value of the field in the first result row otherwise.
+func searchValue(state *SearchCheckState, job *SearchJobContent, results []map[string]any) (float64, bool) {
+	if state.Field == "" {
+		return float64(job.ResultCount), true
+	}
+	if len(results) == 0 {
+		return 0, false
+	}
+	return toFloat(results[0][state.Field])
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	case []any:
+		// multivalue fields are returned as arrays, use the first value
+		if len(v) > 0 {
+			return toFloat(v[0])
+		}
+	}
+	return 0, false
+}
+
+func compare(value float64, operator string, threshold float64) bool {
+	switch operator {
+	case operatorGreaterThan:
+		return value > threshold
+	case operatorGreaterThanOrEqual:
+		return value >= threshold
+	case operatorLessThan:
+		return value < threshold
+	case operatorLessThanOrEqual:
+		return value <= threshold
+	case operatorEqual:
+		return value == threshold
+	case operatorNotEqual:
+		return value != threshold
+	default:
+		return false
+	}
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func searchDeviationTitle(state *SearchCheckState, value float64, found bool) string {
+	if !found {
+		return fmt.Sprintf("Search returned no numeric value for field %q.", state.Field)
+	}
+	return fmt.Sprintf("Search result %s does not match %s %s.", formatValue(value), state.Operator, formatValue(state.Threshold))
+}
+
+func toSearchMetrics(state *SearchCheckState, value float64, found bool, matches bool, now time.Time) []action_kit_api.Metric {
+	label := "result count"
+	if state.Field != "" {
+		label = state.Field
+	}
+
+	tooltip := fmt.Sprintf("Search returned no value for %s", label)
+	if found {
+		tooltip = fmt.Sprintf("Search returned %s = %s", label, formatValue(value))
+	}
+
+	metricState := "danger"
+	if matches {
+		metricState = "success"
+	}
+
+	return []action_kit_api.Metric{
+		{
+			Name: new("Splunk Search"),
+			Metric: map[string]string{
+				searchMetricId:      label,
+				searchMetricLabel:   fmt.Sprintf("%s %s %s", label, state.Operator, formatValue(state.Threshold)),
+				searchMetricState:   metricState,
+				searchMetricTooltip: tooltip,
+			},
+			Timestamp: now,
+			Value:     value,
+		},
+	}
+}
+
+// toSeriesMetrics converts the numeric fields of the search results into metrics for the line chart. Results of a
+// timechart are emitted once per bucket at the bucket's time. As every search covers the whole step, buckets
+// emitted by an earlier search are skipped, and the newest bucket is only emitted once the step is completed, as it
+// is still filling up before. Results without a _time field are emitted at the current time.
+func toSeriesMetrics(state *SearchCheckState, results []map[string]any, completed bool, now time.Time) []action_kit_api.Metric {
+	var newest time.Time
+	for _, row := range results {
+		if rowTime, ok := resultTime(row); ok && rowTime.After(newest) {
+			newest = rowTime
+		}
+	}
+
+	var metrics []action_kit_api.Metric
+	emittedUntil := state.SeriesEmittedUntil
+	for i, row := range results {
+		timestamp := now
+		if rowTime, ok := resultTime(row); ok {
+			if !rowTime.After(state.SeriesEmittedUntil) || (!completed && !rowTime.Before(newest)) {
+				continue
+			}
+			timestamp = rowTime
+			if rowTime.After(emittedUntil) {
+				emittedUntil = rowTime
+			}
+		} else if i > 0 {
+			// without a time, only the first row is comparable between searches
+			break
+		}
+
+		for _, field := range slices.Sorted(maps.Keys(row)) {
+			if strings.HasPrefix(field, "_") {
+				continue
+			}
+			value, ok := toFloat(row[field])
+			if !ok {
+				continue
+			}
+			metrics = append(metrics, action_kit_api.Metric{
+				Name: new(searchSeriesMetricName),
+				Metric: map[string]string{
+					searchMetricSeries: field,
+				},
+				Timestamp: timestamp,
+				Value:     value,
+			})
+		}
+	}
+	state.SeriesEmittedUntil = emittedUntil
+	return metrics
+}
+
+func resultTime(row map[string]any) (time.Time, bool) {
+	raw, ok := row["_time"].(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
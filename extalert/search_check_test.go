@@ -0,0 +1,370 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type MockSearchClient struct {
+	sid          string
+	job          SearchJobContent
+	results      []map[string]any
+	err          error
+	preflightErr error
+	// cancelled records the sids of cancelled search jobs.
+	cancelled *[]string
+}
+
+func (c MockSearchClient) Preflight(_ context.Context, _ ...string) error {
+	return c.preflightErr
+}
+
+func (c MockSearchClient) DispatchSearch(_ context.Context, _ string, _, _ time.Time) (string, error) {
+	return c.sid, c.err
+}
+
+func (c MockSearchClient) SearchJob(_ context.Context, _ string) (*SearchJobContent, error) {
+	return &c.job, c.err
+}
+
+func (c MockSearchClient) SearchResults(_ context.Context, _ string) ([]map[string]any, error) {
+	return c.results, c.err
+}
+
+func (c MockSearchClient) CancelSearch(_ context.Context, sid string) error {
+	if c.cancelled != nil {
+		*c.cancelled = append(*c.cancelled, sid)
+	}
+	return c.err
+}
+
+func TestSearchCheckAction_Describe_NoError(t *testing.T) {
+	action := NewSearchCheckAction(resolveTo[SearchClient](MockSearchClient{}))
+
+	description := action.Describe()
+
+	require.NotNil(t, description)
+	require.Nil(t, description.TargetSelection)
+}
+
+func TestSearchCheckAction_Prepare(t *testing.T) {
+	action := NewSearchCheckAction(resolveTo[SearchClient](MockSearchClient{}))
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{
+			"duration":       1000,
+			"query":          " index=main | stats avg(latency) ",
+			"field":          "avg(latency)",
+			"operator":       operatorLessThan,
+			"threshold":      "500.5",
+			"stateCheckMode": stateCheckModeAllTheTime,
+		},
+		ExecutionId: uuid.UUID{},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "index=main | stats avg(latency)", state.Query)
+	require.Equal(t, "avg(latency)", state.Field)
+	require.Equal(t, operatorLessThan, state.Operator)
+	require.Equal(t, 500.5, state.Threshold)
+	require.Greater(t, state.End, state.Start)
+	require.True(t, state.FailEarly)
+}
+
+func TestSearchCheckAction_Prepare_invalidThreshold(t *testing.T) {
+	action := NewSearchCheckAction(resolveTo[SearchClient](MockSearchClient{}))
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{
+			"duration":       1000,
+			"query":          "index=main",
+			"operator":       operatorGreaterThan,
+			"threshold":      "a lot",
+			"stateCheckMode": stateCheckModeAllTheTime,
+		},
+	})
+
+	require.ErrorContains(t, err, "threshold parameter is not a number")
+}
+
+func TestSearchCheckAction_checkSearch_dispatchesJob(t *testing.T) {
+	state := SearchCheckState{
+		Query:          "index=main",
+		Operator:       operatorGreaterThan,
+		Start:          time.Now().Add(-1 * time.Minute),
+		End:            time.Now().Add(1 * time.Minute),
+		StateCheckMode: stateCheckModeAllTheTime,
+		FailEarly:      true,
+	}
+
+	result, err := checkSearch(t.Context(), &state, MockSearchClient{sid: "sid-1"})
+
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.Nil(t, result.Error)
+	require.Equal(t, "sid-1", state.Sid)
+	require.NotZero(t, state.SearchLatest)
+}
+
+func TestSearchCheckAction_checkSearch_jobRunning(t *testing.T) {
+	state := SearchCheckState{
+		Operator:       operatorGreaterThan,
+		Start:          time.Now().Add(-1 * time.Minute),
+		End:            time.Now().Add(1 * time.Minute),
+		StateCheckMode: stateCheckModeAllTheTime,
+		Sid:            "sid-1",
+	}
+
+	result, err := checkSearch(t.Context(), &state, MockSearchClient{job: SearchJobContent{DispatchState: "RUNNING"}})
+
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.Equal(t, "sid-1", state.Sid)
+}
+
+func TestSearchCheckAction_checkSearch_jobFailed(t *testing.T) {
+	state := SearchCheckState{
+		Operator:       operatorGreaterThan,
+		StateCheckMode: stateCheckModeAllTheTime,
+		Sid:            "sid-1",
+	}
+
+	_, err := checkSearch(t.Context(), &state, MockSearchClient{job: SearchJobContent{IsDone: true, IsFailed: true}})
+
+	require.ErrorContains(t, err, "search job sid-1 failed")
+}
+
+func TestSearchCheckAction_checkSearch_allTheTime_resultCountMatches_running(t *testing.T) {
+	now := time.Now()
+	state := SearchCheckState{
+		Operator:       operatorGreaterThan,
+		Threshold:      0,
+		Start:          now.Add(-1 * time.Minute),
+		End:            now.Add(1 * time.Minute),
+		StateCheckMode: stateCheckModeAllTheTime,
+		FailEarly:      true,
+		Sid:            "sid-1",
+		SearchLatest:   now,
+	}
+
+	result, err := checkSearch(t.Context(), &state, MockSearchClient{job: SearchJobContent{IsDone: true, ResultCount: 3}})
+
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.Nil(t, result.Error)
+	require.Empty(t, state.Sid)
+	require.Equal(t, 3.0, (*result.Metrics)[0].Value)
+	require.Equal(t, "success", (*result.Metrics)[0].Metric[searchMetricState])
+}
+
+func TestSearchCheckAction_checkSearch_allTheTime_resultCountDeviates_failEarly(t *testing.T) {
+	now := time.Now()
+	state := SearchCheckState{
+		Operator:       operatorGreaterThan,
+		Threshold:      0,
+		Start:          now.Add(-1 * time.Minute),
+		End:            now.Add(1 * time.Minute),
+		StateCheckMode: stateCheckModeAllTheTime,
+		FailEarly:      true,
+		Sid:            "sid-1",
+		SearchLatest:   now,
+	}
+
+	result, err := checkSearch(t.Context(), &state, MockSearchClient{job: SearchJobContent{IsDone: true, ResultCount: 0}})
+
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.NotNil(t, result.Error)
+	require.Equal(t, "Search result 0 does not match > 0.", result.Error.Title)
+}
+
+func TestSearchCheckAction_checkSearch_allTheTime_failAtEnd(t *testing.T) {
+	now := time.Now()
+	client := MockSearchClient{
+		job:     SearchJobContent{IsDone: true, ResultCount: 1},
+		results: []map[string]any{{"avg(latency)": "750.25"}},
+	}
+	state := SearchCheckState{
+		Field:          "avg(latency)",
+		Operator:       operatorLessThan,
+		Threshold:      500,
+		Start:          now.Add(-1 * time.Minute),
+		End:            now.Add(1 * time.Minute),
+		StateCheckMode: stateCheckModeAllTheTime,
+		FailEarly:      false,
+		Sid:            "sid-1",
+		SearchLatest:   now,
+	}
+
+	result, err := checkSearch(t.Context(), &state, client)
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.Nil(t, result.Error)
+	require.Equal(t, "Search result 750.25 does not match < 500.", state.DeviationTitle)
+
+	client.results = []map[string]any{{"avg(latency)": "100"}}
+	state.Sid = "sid-2"
+	state.SearchLatest = now.Add(2 * time.Minute)
+	result, err = checkSearch(t.Context(), &state, client)
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+	require.NotNil(t, result.Error)
+	require.Equal(t, "Search result 750.25 does not match < 500.", result.Error.Title)
+}
+
+func TestSearchCheckAction_checkSearch_fieldMissing(t *testing.T) {
+	now := time.Now()
+	state := SearchCheckState{
+		Field:          "count",
+		Operator:       operatorGreaterThan,
+		Start:          now.Add(-1 * time.Minute),
+		End:            now.Add(1 * time.Minute),
+		StateCheckMode: stateCheckModeAllTheTime,
+		FailEarly:      true,
+		Sid:            "sid-1",
+		SearchLatest:   now,
+	}
+
+	result, err := checkSearch(t.Context(), &state, MockSearchClient{job: SearchJobContent{IsDone: true}})
+
+	require.NoError(t, err)
+	require.NotNil(t, result.Error)
+	require.Equal(t, `Search returned no numeric value for field "count".`, result.Error.Title)
+}
+
+func TestSearchCheckAction_checkSearch_atLeastOnce(t *testing.T) {
+	now := time.Now()
+	client := MockSearchClient{
+		job:     SearchJobContent{IsDone: true},
+		results: []map[string]any{{"count": "5"}},
+	}
+	state := SearchCheckState{
+		Field:          "count",
+		Operator:       operatorGreaterThanOrEqual,
+		Threshold:      10,
+		Start:          now.Add(-1 * time.Minute),
+		End:            now.Add(1 * time.Minute),
+		StateCheckMode: stateCheckModeAtLeastOnce,
+		Sid:            "sid-1",
+		SearchLatest:   now,
+	}
+
+	result, err := checkSearch(t.Context(), &state, client)
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+	require.False(t, state.StateCheckSuccess)
+
+	client.results = []map[string]any{{"count": "12"}}
+	state.Sid = "sid-2"
+	state.SearchLatest = now.Add(2 * time.Minute)
+	result, err = checkSearch(t.Context(), &state, client)
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+	require.Nil(t, result.Error)
+}
+
+func TestSearchCheckAction_checkSearch_atLeastOnce_neverMatched(t *testing.T) {
+	now := time.Now()
+	state := SearchCheckState{
+		Operator:       operatorGreaterThan,
+		Threshold:      0,
+		Start:          now.Add(-2 * time.Minute),
+		End:            now.Add(-1 * time.Minute),
+		StateCheckMode: stateCheckModeAtLeastOnce,
+		Sid:            "sid-1",
+		SearchLatest:   now,
+	}
+
+	result, err := checkSearch(t.Context(), &state, MockSearchClient{job: SearchJobContent{IsDone: true}})
+
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+	require.NotNil(t, result.Error)
+	require.Equal(t, "Search result never matched > 0.", result.Error.Title)
+}
+
+func TestSearchCheckAction_checkSearch_error_on_dispatch(t *testing.T) {
+	state := SearchCheckState{
+		StateCheckMode: stateCheckModeAllTheTime,
+	}
+
+	_, err := checkSearch(t.Context(), &state, MockSearchClient{err: errors.New("dispatch error")})
+
+	require.Error(t, err)
+}
+
+func TestSearchCheckAction_toSeriesMetrics_timechart(t *testing.T) {
+	now := time.Now()
+	state := SearchCheckState{}
+	results := []map[string]any{
+		{"_time": "2026-01-01T10:00:00.000+00:00", "_span": "60", "api": "0.5", "web": "1.5"},
+		{"_time": "2026-01-01T10:01:00.000+00:00", "_span": "60", "api": "0.25", "web": ""},
+		{"_time": "2026-01-01T10:02:00.000+00:00", "_span": "60", "api": "0.75", "web": "2"},
+	}
+
+	// the newest bucket is still filling up and must not be emitted yet
+	metrics := toSeriesMetrics(&state, results, false, now)
+	require.Len(t, metrics, 3)
+	for _, metric := range metrics {
+		require.Equal(t, searchSeriesMetricName, *metric.Name)
+		require.True(t, metric.Timestamp.Before(time.Date(2026, 1, 1, 10, 2, 0, 0, time.UTC)))
+	}
+	require.Equal(t, time.Date(2026, 1, 1, 10, 1, 0, 0, time.UTC), state.SeriesEmittedUntil.UTC())
+
+	// already emitted buckets are skipped, the newest one is emitted once the step is completed
+	metrics = toSeriesMetrics(&state, results, true, now)
+	require.Len(t, metrics, 2)
+	values := map[string]float64{}
+	for _, metric := range metrics {
+		values[metric.Metric[searchMetricSeries]] = metric.Value
+		require.Equal(t, time.Date(2026, 1, 1, 10, 2, 0, 0, time.UTC), metric.Timestamp.UTC())
+	}
+	require.Equal(t, map[string]float64{"api": 0.75, "web": 2}, values)
+}
+
+func TestSearchCheckAction_toSeriesMetrics_stats(t *testing.T) {
+	now := time.Now()
+	state := SearchCheckState{}
+
+	metrics := toSeriesMetrics(&state, []map[string]any{{"avg(latency)": "120.5", "host": "web-1"}, {"avg(latency)": "99"}}, false, now)
+
+	require.Len(t, metrics, 1)
+	require.Equal(t, "avg(latency)", metrics[0].Metric[searchMetricSeries])
+	require.Equal(t, 120.5, metrics[0].Value)
+	require.Equal(t, now, metrics[0].Timestamp)
+}
+
+func TestSearchCheckAction_Stop_cancelsJobInFlight(t *testing.T) {
+	var cancelled []string
+	action := NewSearchCheckAction(resolveTo[SearchClient](MockSearchClient{cancelled: &cancelled})).(action_kit_sdk.ActionWithStop[SearchCheckState])
+	state := &SearchCheckState{Instance: "production", Sid: "sid"}
+
+	_, err := action.Stop(t.Context(), state)
+	require.NoError(t, err)
+	require.Equal(t, []string{"sid"}, cancelled)
+	require.Empty(t, state.Sid)
+
+	_, err = action.Stop(t.Context(), state)
+	require.NoError(t, err)
+	require.Equal(t, []string{"sid"}, cancelled)
+}
+
+func TestSearchCheckAction_Stop_ignoresCancelFailure(t *testing.T) {
+	action := NewSearchCheckAction(resolveTo[SearchClient](MockSearchClient{err: errors.New("connection refused")})).(action_kit_sdk.ActionWithStop[SearchCheckState])
+	state := &SearchCheckState{Sid: "sid"}
+
+	_, err := action.Stop(t.Context(), state)
+
+	require.NoError(t, err)
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSearch_DispatchPollAndFetchResults(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /services/search/jobs", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "search index=main | stats count", r.PostForm.Get("search"))
+		require.Equal(t, "946684800", r.PostForm.Get("earliest_time"))
+		require.Equal(t, "946684860", r.PostForm.Get("latest_time"))
+		require.Equal(t, "120", r.PostForm.Get("timeout"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"1234.5"}`))
+	})
+	mux.HandleFunc("GET /services/search/jobs/1234.5", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"entry":[{"name":"index=main | stats count","content":{"sid":"1234.5","dispatchState":"DONE","isDone":true,"isFailed":false,"resultCount":1}}]}`))
+	})
+	mux.HandleFunc("GET /services/search/jobs/1234.5/results", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "1000", r.URL.Query().Get("count"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[{"count":"42"}]}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := &SplunkClient{client: resty.New().SetBaseURL(srv.URL).SetHeader("Content-Type", "application/json")}
+	ctx := context.Background()
+
+	sid, err := c.DispatchSearch(ctx, "index=main | stats count", time.Unix(946684800, 0), time.Unix(946684860, 0))
+	require.NoError(t, err)
+	require.Equal(t, "1234.5", sid)
+
+	job, err := c.SearchJob(ctx, sid)
+	require.NoError(t, err)
+	require.True(t, job.IsDone)
+	require.Equal(t, 1, job.ResultCount)
+
+	results, err := c.SearchResults(ctx, sid)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"count": "42"}}, results)
+}
+
+func TestSplunkClient_CancelSearch(t *testing.T) {
+	var actions []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /services/search/jobs/1234.5/control", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		actions = append(actions, r.PostForm.Get("action"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"messages":[{"type":"INFO","text":"Search job cancelled."}]}`))
+	})
+	mux.HandleFunc("POST /services/search/jobs/expired/control", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"messages":[{"type":"FATAL","text":"Unknown sid."}]}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := &SplunkClient{client: resty.New().SetBaseURL(srv.URL).SetHeader("Content-Type", "application/json")}
+
+	require.NoError(t, c.CancelSearch(t.Context(), "1234.5"))
+	require.NoError(t, c.CancelSearch(t.Context(), "expired"))
+	require.Equal(t, []string{"cancel"}, actions)
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	require.Equal(t, "search index=main", normalizeQuery("index=main"))
+	require.Equal(t, "search index=main", normalizeQuery(" search index=main "))
+	require.Equal(t, "| tstats count where index=main", normalizeQuery("| tstats count where index=main"))
+}
@@ -0,0 +1,372 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type StreamClient interface {
+	PreflightClient
+	ExportSearch(ctx context.Context, query string) (io.ReadCloser, error)
+}
+
+type StreamCheckAction struct {
+	Clients ClientResolver[StreamClient]
+}
+
+var (
+	_ action_kit_sdk.Action[StreamCheckState]           = (*StreamCheckAction)(nil)
+	_ action_kit_sdk.ActionWithStatus[StreamCheckState] = (*StreamCheckAction)(nil)
+	_ action_kit_sdk.ActionWithStop[StreamCheckState]   = (*StreamCheckAction)(nil)
+)
+
+type StreamCheckState struct {
+	ExecutionId uuid.UUID
+	Instance    string
+	Query       string
+	Field       string
+	Operator    string
+	Threshold   float64
+	Start       time.Time
+	End         time.Time
+	FailEarly   bool
+}
+
+// streamGracePeriod is how long a stream may stay open after the end of the step, in case the last status call is late.
+const streamGracePeriod = 30 * time.Second
+
+// searchStreams holds the open export streams by execution id, as they cannot be part of the serialized state.
+var searchStreams = sync.Map{}
+
+type searchStream struct {
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	rows        int
+	matchedRows int
+	// previewRows and previewMatchedRows count the rows of the latest preview of a transforming search, which replaces
+	// the previous preview.
+	previewRows        int
+	previewMatchedRows int
+	// firstMatch describes the first row matching the failure predicate, empty if none matched so far.
+	firstMatch string
+	err        error
+}
+
+func NewStreamCheckAction(clients ClientResolver[StreamClient]) action_kit_sdk.Action[StreamCheckState] {
+	return &StreamCheckAction{
+		Clients: clients,
+	}
+}
+
+func (a *StreamCheckAction) NewEmptyState() StreamCheckState {
+	return StreamCheckState{}
+}
+
+func (a *StreamCheckAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          fmt.Sprintf("%s.stream-check", searchType),
+		Label:       "Real-Time Search Check",
+		Description: "Stream the results of a real-time SPL search and fail as soon as a result matches the failure condition.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:        new(searchIcon),
+		Technology:  new("Splunk"),
+		Category:    new("Monitoring"),
+		Kind:        action_kit_api.Check,
+		TimeControl: action_kit_api.TimeControlInternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Duration",
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("30s"),
+				Required:     new(true),
+			},
+			{
+				Name:        "query",
+				Label:       "SPL Query",
+				Description: new("The real-time search to stream, for example 'index=main sourcetype=access_combined'."),
+				Type:        action_kit_api.ActionParameterTypeTextarea,
+				Required:    new(true),
+			},
+			{
+				Name:        "field",
+				Label:       "Field",
+				Description: new("Numeric field of the streamed results to check, for example 'latency'. If empty, every streamed result is a failure."),
+				Type:        action_kit_api.ActionParameterTypeString,
+				Required:    new(false),
+			},
+			{
+				Name:         "operator",
+				Label:        "Failure Operator",
+				Description:  new("A result fails the check if the field compared to the threshold with this operator is true."),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new(operatorGreaterThan),
+				Options:      new(operatorOptions()),
+				Required:     new(true),
+			},
+			{
+				Name:         "threshold",
+				Label:        "Failure Threshold",
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new("0"),
+				Required:     new(true),
+			},
+			{
+				Name:         "failEarly",
+				Label:        "Fail early",
+				Description:  new("If enabled, the check fails as soon as a failing result is streamed. If disabled, the check keeps streaming for the whole duration and only fails at the end of the step."),
+				Type:         action_kit_api.ActionParameterTypeBoolean,
+				DefaultValue: new("true"),
+				Advanced:     new(true),
+				Required:     new(false),
+			},
+			instanceParameter(),
+		},
+		Widgets: new([]action_kit_api.Widget{
+			action_kit_api.StateOverTimeWidget{
+				Type:  action_kit_api.ComSteadybitWidgetStateOverTime,
+				Title: "Streamed Results",
+				Identity: action_kit_api.StateOverTimeWidgetIdentityConfig{
+					From: searchMetricId,
+				},
+				Label: action_kit_api.StateOverTimeWidgetLabelConfig{
+					From: searchMetricLabel,
+				},
+				State: action_kit_api.StateOverTimeWidgetStateConfig{
+					From: searchMetricState,
+				},
+				Tooltip: action_kit_api.StateOverTimeWidgetTooltipConfig{
+					From: searchMetricTooltip,
+				},
+				Value: new(action_kit_api.StateOverTimeWidgetValueConfig{
+					Hide: new(true),
+				}),
+			},
+		}),
+		Status: new(action_kit_api.MutatingEndpointReferenceWithCallInterval{
+			CallInterval: new("1s"),
+		}),
+		Stop: new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (a *StreamCheckAction) Prepare(ctx context.Context, state *StreamCheckState, request action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	query := strings.TrimSpace(extutil.ToString(request.Config["query"]))
+	if query == "" {
+		return nil, fmt.Errorf("query parameter is missing")
+	}
+
+	operator := extutil.ToString(request.Config["operator"])
+	switch operator {
+	case operatorGreaterThan, operatorGreaterThanOrEqual, operatorLessThan, operatorLessThanOrEqual, operatorEqual, operatorNotEqual:
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", operator)
+	}
+
+	threshold, err := strconv.ParseFloat(strings.TrimSpace(extutil.ToString(request.Config["threshold"])), 64)
+	if err != nil {
+		return nil, fmt.Errorf("threshold parameter is not a number: %w", err)
+	}
+
+	start := time.Now()
+	end := start.Add(time.Duration(extutil.ToInt64(request.Config["duration"])) * time.Millisecond)
+
+	state.ExecutionId = request.ExecutionId
+	state.Instance = extutil.ToString(request.Config["instance"])
+	state.Query = query
+	state.Field = strings.TrimSpace(extutil.ToString(request.Config["field"]))
+	state.Operator = operator
+	state.Threshold = threshold
+	state.Start = start
+	state.End = end
+	state.FailEarly = true
+	if request.Config["failEarly"] != nil {
+		state.FailEarly = extutil.ToBool(request.Config["failEarly"])
+	}
+
+	if err := preflight(ctx, a.Clients, state.Instance, capabilitySearch, capabilityRtSearch); err != nil {
+		return nil, err
+	}
+
+	log.Trace().Any("state", state).Msg("stream check action state")
+
+	return nil, nil
+}
+
+func (a *StreamCheckAction) Start(ctx context.Context, state *StreamCheckState) (*action_kit_api.StartResult, error) {
+	client, err := a.Clients(state.Instance)
+	if err != nil {
+		return nil, err
+	}
+
+	// The stream outlives the start request, so it must not be cancelled with it. The deadline closes the stream even
+	// if neither a final status nor stop call arrives.
+	streamCtx, cancel := context.WithDeadline(context.WithoutCancel(ctx), state.End.Add(streamGracePeriod))
+	body, err := client.ExportSearch(streamCtx, state.Query)
+	if err != nil {
+		cancel()
+		return nil, toExtensionError(err)
+	}
+
+	stream := &searchStream{cancel: cancel}
+	searchStreams.Store(state.ExecutionId, stream)
+	go stream.consume(streamCtx, body, state.Field, state.Operator, state.Threshold)
+
+	return nil, nil
+}
+
+func (a *StreamCheckAction) Status(_ context.Context, state *StreamCheckState) (*action_kit_api.StatusResult, error) {
+	value, ok := searchStreams.Load(state.ExecutionId)
+	if !ok {
+		return nil, fmt.Errorf("search stream for execution %s not found", state.ExecutionId)
+	}
+	stream := value.(*searchStream)
+
+	result, err := checkStream(state, stream)
+	if err != nil || result.Completed || result.Error != nil {
+		stopStream(state.ExecutionId)
+	}
+	return result, err
+}
+
+func (a *StreamCheckAction) Stop(_ context.Context, state *StreamCheckState) (*action_kit_api.StopResult, error) {
+	stopStream(state.ExecutionId)
+	return nil, nil
+}
+
+func stopStream(executionId uuid.UUID) {
+	if value, ok := searchStreams.LoadAndDelete(executionId); ok {
+		value.(*searchStream).cancel()
+	}
+}
+
+func checkStream(state *StreamCheckState, stream *searchStream) (*action_kit_api.StatusResult, error) {
+	now := time.Now()
+
+	stream.mu.Lock()
+	rows, matchedRows, firstMatch, streamErr := stream.rows+stream.previewRows, stream.matchedRows+stream.previewMatchedRows, stream.firstMatch, stream.err
+	stream.mu.Unlock()
+
+	if streamErr != nil {
+		return nil, streamErr
+	}
+
+	completed := now.After(state.End)
+	var checkError *action_kit_api.ActionKitError
+	if firstMatch != "" && (state.FailEarly || completed) {
+		checkError = new(action_kit_api.ActionKitError{
+			Title:  firstMatch,
+			Status: extutil.Ptr(action_kit_api.Failed),
+		})
+	}
+
+	metricState := "success"
+	if matchedRows > 0 {
+		metricState = "danger"
+	}
+
+	return &action_kit_api.StatusResult{
+		Completed: completed,
+		Error:     checkError,
+		Metrics: new([]action_kit_api.Metric{
+			{
+				Name: new("Splunk Search Stream"),
+				Metric: map[string]string{
+					searchMetricId:      "stream",
+					searchMetricLabel:   "Failing results",
+					searchMetricState:   metricState,
+					searchMetricTooltip: fmt.Sprintf("%d of %d streamed results failed the check", matchedRows, rows),
+				},
+				Timestamp: now,
+				Value:     float64(matchedRows),
+			},
+		}),
+	}, nil
+}
+
+func (s *searchStream) consume(ctx context.Context, body io.ReadCloser, field, operator string, threshold float64) {
+	defer s.cancel()
+	defer func() { _ = body.Close() }()
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var row ExportRow
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			log.Debug().Err(err).Msg("Skipping unparsable row of the search stream")
+			continue
+		}
+		if row.Result == nil {
+			continue
+		}
+		// real-time transforming searches, e.g. stats, only stream previews, so their rows are evaluated as well
+		s.record(row.Preview, row.Offset, matchStreamRow(row.Result, field, operator, threshold, time.Now()))
+	}
+
+	if ctx.Err() != nil {
+		// the stream was stopped on purpose or outlived the step
+		return
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = errors.New("search stream ended before the end of the step")
+	}
+	s.mu.Lock()
+	s.err = fmt.Errorf("failed to read search stream: %w", err)
+	s.mu.Unlock()
+}
+
+func (s *searchStream) record(preview bool, offset int, match string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if preview {
+		if offset == 0 {
+			s.previewRows, s.previewMatchedRows = 0, 0
+		}
+		s.previewRows++
+	} else {
+		s.rows++
+	}
+	if match != "" {
+		if preview {
+			s.previewMatchedRows++
+		} else {
+			s.matchedRows++
+		}
+		if s.firstMatch == "" {
+			s.firstMatch = match
+		}
+	}
+}
+
+// matchStreamRow returns a description of the row if it matches the failure predicate, an empty string otherwise.
+func matchStreamRow(result map[string]any, field, operator string, threshold float64, now time.Time) string {
+	at := now.UTC().Format(time.RFC3339Nano)
+	if field == "" {
+		return fmt.Sprintf("Search streamed a result at %s.", at)
+	}
+	value, ok := toFloat(result[field])
+	if !ok || !compare(value, operator, threshold) {
+		return ""
+	}
+	return fmt.Sprintf("Search streamed a result with %s = %s (%s %s) at %s.", field, formatValue(value), operator, formatValue(threshold), at)
+}
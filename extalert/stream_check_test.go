@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+	"time"
+)
+
+type MockStreamClient struct {
+	body         io.ReadCloser
+	err          error
+	preflightErr error
+	// streamCtx records the context the stream was opened with.
+	streamCtx *context.Context
+}
+
+func (c MockStreamClient) Preflight(_ context.Context, _ ...string) error {
+	return c.preflightErr
+}
+
+func (c MockStreamClient) ExportSearch(ctx context.Context, _ string) (io.ReadCloser, error) {
+	if c.streamCtx != nil {
+		*c.streamCtx = ctx
+	}
+	return c.body, c.err
+}
+
+func TestStreamCheckAction_Describe_NoError(t *testing.T) {
+	action := NewStreamCheckAction(resolveTo[StreamClient](MockStreamClient{}))
+
+	description := action.Describe()
+
+	require.NotNil(t, description)
+}
+
+func TestStreamCheckAction_Prepare(t *testing.T) {
+	action := NewStreamCheckAction(resolveTo[StreamClient](MockStreamClient{}))
+	state := action.NewEmptyState()
+	executionId := uuid.New()
+
+	_, err := action.Prepare(t.Context(), &state, action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{
+			"duration":  1000,
+			"query":     "index=main",
+			"field":     "latency",
+			"operator":  operatorGreaterThan,
+			"threshold": "500",
+			"failEarly": false,
+		},
+		ExecutionId: executionId,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, executionId, state.ExecutionId)
+	require.Equal(t, "latency", state.Field)
+	require.Equal(t, 500.0, state.Threshold)
+	require.False(t, state.FailEarly)
+}
+
+func TestStreamCheckAction_failsEarlyOnMatchingRow(t *testing.T) {
+	reader, writer := io.Pipe()
+	action := NewStreamCheckAction(resolveTo[StreamClient](MockStreamClient{body: reader})).(*StreamCheckAction)
+	state := StreamCheckState{
+		ExecutionId: uuid.New(),
+		Field:       "latency",
+		Operator:    operatorGreaterThan,
+		Threshold:   500,
+		Start:       time.Now(),
+		End:         time.Now().Add(1 * time.Minute),
+		FailEarly:   true,
+	}
+
+	_, err := action.Start(t.Context(), &state)
+	require.NoError(t, err)
+
+	_, _ = writer.Write([]byte(`{"preview":false,"result":{"latency":"120"}}` + "\n"))
+	result, err := action.Status(t.Context(), &state)
+	require.NoError(t, err)
+	require.Nil(t, result.Error)
+
+	_, _ = writer.Write([]byte(`{"preview":false,"result":{"latency":"750"}}` + "\n"))
+	require.Eventually(t, func() bool {
+		result, err = action.Status(t.Context(), &state)
+		return err == nil && result.Error != nil
+	}, time.Second, 10*time.Millisecond)
+	require.Contains(t, result.Error.Title, "Search streamed a result with latency = 750 (> 500)")
+
+	_, ok := searchStreams.Load(state.ExecutionId)
+	require.False(t, ok, "stream must be closed once the check failed")
+}
+
+func TestStreamCheckAction_evaluatesLatestPreview(t *testing.T) {
+	reader, writer := io.Pipe()
+	action := NewStreamCheckAction(resolveTo[StreamClient](MockStreamClient{body: reader})).(*StreamCheckAction)
+	state := StreamCheckState{
+		ExecutionId: uuid.New(),
+		Field:       "count",
+		Operator:    operatorGreaterThan,
+		Threshold:   10,
+		Start:       time.Now(),
+		End:         time.Now().Add(1 * time.Minute),
+		FailEarly:   false,
+	}
+
+	_, err := action.Start(t.Context(), &state)
+	require.NoError(t, err)
+	defer stopStream(state.ExecutionId)
+
+	_, _ = writer.Write([]byte(`{"preview":true,"offset":0,"result":{"host":"a","count":"5"}}` + "\n"))
+	_, _ = writer.Write([]byte(`{"preview":true,"offset":1,"result":{"host":"b","count":"50"}}` + "\n"))
+	require.Eventually(t, func() bool {
+		result, err := action.Status(t.Context(), &state)
+		return err == nil && (*result.Metrics)[0].Metric[searchMetricTooltip] == "1 of 2 streamed results failed the check"
+	}, time.Second, 10*time.Millisecond)
+
+	// the next preview replaces the previous one
+	_, _ = writer.Write([]byte(`{"preview":true,"offset":0,"result":{"host":"a","count":"6"}}` + "\n"))
+	require.Eventually(t, func() bool {
+		result, err := action.Status(t.Context(), &state)
+		return err == nil && (*result.Metrics)[0].Metric[searchMetricTooltip] == "0 of 1 streamed results failed the check"
+	}, time.Second, 10*time.Millisecond)
+
+	state.End = time.Now().Add(-1 * time.Second)
+	result, err := checkStream(&state, mustLoadStream(t, state.ExecutionId))
+	require.NoError(t, err)
+	require.NotNil(t, result.Error, "a failing preview fails the check")
+	require.Contains(t, result.Error.Title, "Search streamed a result with count = 50 (> 10)")
+}
+
+func mustLoadStream(t *testing.T, executionId uuid.UUID) *searchStream {
+	value, ok := searchStreams.Load(executionId)
+	require.True(t, ok)
+	return value.(*searchStream)
+}
+
+func TestStreamCheckAction_failAtEnd(t *testing.T) {
+	reader, writer := io.Pipe()
+	action := NewStreamCheckAction(resolveTo[StreamClient](MockStreamClient{body: reader})).(*StreamCheckAction)
+	state := StreamCheckState{
+		ExecutionId: uuid.New(),
+		Start:       time.Now(),
+		End:         time.Now().Add(1 * time.Minute),
+		FailEarly:   false,
+	}
+
+	_, err := action.Start(t.Context(), &state)
+	require.NoError(t, err)
+
+	_, _ = writer.Write([]byte(`{"preview":false,"result":{"_raw":"error"}}` + "\n"))
+	require.Eventually(t, func() bool {
+		result, err := action.Status(t.Context(), &state)
+		return err == nil && (*result.Metrics)[0].Value == 1
+	}, time.Second, 10*time.Millisecond)
+
+	result, err := action.Status(t.Context(), &state)
+	require.NoError(t, err)
+	require.False(t, result.Completed)
+	require.Nil(t, result.Error)
+
+	state.End = time.Now().Add(-1 * time.Second)
+	result, err = action.Status(t.Context(), &state)
+	require.NoError(t, err)
+	require.True(t, result.Completed)
+	require.NotNil(t, result.Error)
+	require.Contains(t, result.Error.Title, "Search streamed a result at")
+}
+
+func TestStreamCheckAction_streamEndsUnexpectedly(t *testing.T) {
+	reader, writer := io.Pipe()
+	action := NewStreamCheckAction(resolveTo[StreamClient](MockStreamClient{body: reader})).(*StreamCheckAction)
+	state := StreamCheckState{
+		ExecutionId: uuid.New(),
+		Start:       time.Now(),
+		End:         time.Now().Add(1 * time.Minute),
+		FailEarly:   true,
+	}
+
+	_, err := action.Start(t.Context(), &state)
+	require.NoError(t, err)
+	_ = writer.Close()
+
+	require.Eventually(t, func() bool {
+		_, err = action.Status(t.Context(), &state)
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+	require.ErrorContains(t, err, "search stream ended before the end of the step")
+}
+
+func TestStreamCheckAction_Stop(t *testing.T) {
+	reader, _ := io.Pipe()
+	action := NewStreamCheckAction(resolveTo[StreamClient](MockStreamClient{body: reader})).(*StreamCheckAction)
+	state := StreamCheckState{
+		ExecutionId: uuid.New(),
+		End:         time.Now().Add(1 * time.Minute),
+	}
+
+	_, err := action.Start(t.Context(), &state)
+	require.NoError(t, err)
+
+	_, err = action.Stop(t.Context(), &state)
+	require.NoError(t, err)
+
+	_, err = action.Status(t.Context(), &state)
+	require.ErrorContains(t, err, "not found")
+}
+
+func TestStreamCheckAction_streamIsBoundToStepEnd(t *testing.T) {
+	var streamCtx context.Context
+	reader, _ := io.Pipe()
+	action := NewStreamCheckAction(resolveTo[StreamClient](MockStreamClient{body: reader, streamCtx: &streamCtx})).(*StreamCheckAction)
+	state := StreamCheckState{
+		ExecutionId: uuid.New(),
+		End:         time.Now().Add(1 * time.Minute),
+	}
+	startCtx, cancelStart := context.WithCancel(t.Context())
+
+	_, err := action.Start(startCtx, &state)
+	require.NoError(t, err)
+	cancelStart()
+
+	deadline, ok := streamCtx.Deadline()
+	require.True(t, ok)
+	require.Equal(t, state.End.Add(streamGracePeriod), deadline)
+	require.NoError(t, streamCtx.Err(), "the stream must outlive the start request")
+
+	_, err = action.Stop(t.Context(), &state)
+	require.NoError(t, err)
+	require.Error(t, streamCtx.Err())
+}
+
+func TestStreamCheckAction_Start_error(t *testing.T) {
+	action := NewStreamCheckAction(resolveTo[StreamClient](MockStreamClient{err: errors.New("export error")}))
+	state := StreamCheckState{ExecutionId: uuid.New()}
+
+	_, err := action.Start(t.Context(), &state)
+
+	require.Error(t, err)
+}
@@ -3,11 +3,26 @@
 
 package extalert
 
+import (
+	"encoding/json"
+	"strconv"
+)
+
 type Response struct {
 	Paging  Paging  `json:"paging"`
 	Entries []Entry `json:"entry"`
 }
 
+// ErrorResponse is returned by Splunk for failed requests.
+type ErrorResponse struct {
+	Messages []Message `json:"messages"`
+}
+
+type Message struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
 type Paging struct {
 	Total   int `json:"total"`
 	PerPage int `json:"perPage"`
@@ -20,15 +35,51 @@ type Entry struct {
 	Author  string  `json:"author"`
 	Content Content `json:"content"`
 	Links   Links   `json:"links"`
+	ACL     ACL     `json:"acl"`
+}
+
+// ACL describes the app context and the permissions of an entry.
+type ACL struct {
+	App     string `json:"app"`
+	Owner   string `json:"owner"`
+	Sharing string `json:"sharing"`
 }
 
 type Content struct {
 	Severity    Severity `json:"alert.severity"`
 	TriggerTime int64    `json:"trigger_time"`
+	// FiredSeverity, Sid and TriggeredAlerts are only set on fired alerts.
+	FiredSeverity   Severity `json:"severity"`
+	Sid             string   `json:"sid"`
+	TriggeredAlerts Count    `json:"triggered_alerts"`
+	// Datatype, CurrentDBSizeMB, TotalEventCount, MinTime, MaxTime and FrozenTimePeriodInSecs are only set on indexes.
+	Datatype               string `json:"datatype"`
+	CurrentDBSizeMB        Count  `json:"currentDBSizeMB"`
+	TotalEventCount        Count  `json:"totalEventCount"`
+	MinTime                string `json:"minTime"`
+	MaxTime                string `json:"maxTime"`
+	FrozenTimePeriodInSecs Count  `json:"frozenTimePeriodInSecs"`
+	// CronSchedule, IsScheduled, Disabled, AlertType and Actions are only set on saved searches.
+	CronSchedule string `json:"cron_schedule"`
+	IsScheduled  Flag   `json:"is_scheduled"`
+	Disabled     Flag   `json:"disabled"`
+	AlertType    string `json:"alert_type"`
+	// Actions is the comma separated list of the alert actions, e.g. "email,webhook".
+	Actions string `json:"actions"`
+}
+
+// AlertSeverity returns the severity of a fired alert, falling back to the severity of the saved search.
+func (c Content) AlertSeverity() Severity {
+	if c.FiredSeverity != 0 {
+		return c.FiredSeverity
+	}
+	return c.Severity
 }
 
 type Links struct {
 	Alerts string `json:"alerts"`
+	// Dispatch is only set on saved searches and links to the endpoint running the saved search.
+	Dispatch string `json:"dispatch"`
 }
 
 type Severity int
@@ -60,3 +111,118 @@ func (s Severity) String() string {
 		return "Unknown"
 	}
 }
+
+// Count is a number Splunk reports either as JSON number or as string, depending on the endpoint and version.
+type Count int
+
+func (c *Count) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case float64:
+		*c = Count(v)
+	case string:
+		if v == "" {
+			*c = 0
+			return nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		*c = Count(n)
+	}
+	return nil
+}
+
+// Flag is a boolean Splunk reports either as JSON boolean, as number or as string, depending on the endpoint and
+// version.
+type Flag bool
+
+func (f *Flag) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case bool:
+		*f = Flag(v)
+	case float64:
+		*f = v != 0
+	case string:
+		if v == "" {
+			*f = false
+			return nil
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		*f = Flag(b)
+	}
+	return nil
+}
+
+type DispatchResponse struct {
+	Sid string `json:"sid"`
+}
+
+type SearchJobResponse struct {
+	Entries []SearchJobEntry `json:"entry"`
+}
+
+type SearchJobEntry struct {
+	Name    string           `json:"name"`
+	Content SearchJobContent `json:"content"`
+}
+
+type SearchJobContent struct {
+	Sid           string `json:"sid"`
+	DispatchState string `json:"dispatchState"`
+	IsDone        bool   `json:"isDone"`
+	IsFailed      bool   `json:"isFailed"`
+	ResultCount   int    `json:"resultCount"`
+}
+
+type SearchResultsResponse struct {
+	Results []map[string]any `json:"results"`
+}
+
+type ExportRow struct {
+	Preview bool `json:"preview"`
+	// Offset is the position of the row in its result set, 0 for the first row of a new preview.
+	Offset int            `json:"offset"`
+	Result map[string]any `json:"result"`
+}
+
+type ServerInfoResponse struct {
+	Entries []ServerInfoEntry `json:"entry"`
+}
+
+type ServerInfoEntry struct {
+	Content ServerInfoContent `json:"content"`
+}
+
+type ServerInfoContent struct {
+	ServerName string `json:"serverName"`
+	Version    string `json:"version"`
+	Build      string `json:"build"`
+	// InstanceType is "cloud" for Splunk Cloud Platform.
+	InstanceType string `json:"instance_type"`
+}
+
+type CurrentContextResponse struct {
+	Entries []CurrentContextEntry `json:"entry"`
+}
+
+type CurrentContextEntry struct {
+	Content CurrentContextContent `json:"content"`
+}
+
+type CurrentContextContent struct {
+	Username     string   `json:"username"`
+	Roles        []string `json:"roles"`
+	Capabilities []string `json:"capabilities"`
+}
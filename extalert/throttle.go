@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"expvar"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+	"net/http"
+	"time"
+)
+
+// Throttling metrics per Splunk instance, published by expvar at /debug/vars.
+var (
+	throttledRequests   = expvar.NewMap("splunk_throttled_requests")
+	throttledWaitMillis = expvar.NewMap("splunk_throttled_wait_ms")
+	inFlightRequests    = expvar.NewMap("splunk_in_flight_requests")
+)
+
+// throttledTransport limits the request rate and the number of concurrent requests to a Splunk instance, shared by
+// all checks and the discovery. Requests wait for their turn until their context is done. Each retry and login is a
+// request of its own. A request stops counting as in flight once the response headers are received, so long-running
+// search streams don't block other requests.
+type throttledTransport struct {
+	next     http.RoundTripper
+	instance string
+	// limiter is nil if the request rate is unlimited.
+	limiter *rate.Limiter
+	// inFlight is nil if the number of concurrent requests is unlimited.
+	inFlight chan struct{}
+}
+
+func newThrottledTransport(next http.RoundTripper, instance string, requestsPerSecond float64, burst int, maxConcurrentRequests int) http.RoundTripper {
+	if requestsPerSecond <= 0 && maxConcurrentRequests <= 0 {
+		return next
+	}
+	transport := &throttledTransport{
+		next:     next,
+		instance: instance,
+	}
+	if requestsPerSecond > 0 {
+		transport.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), max(burst, 1))
+	}
+	if maxConcurrentRequests > 0 {
+		transport.inFlight = make(chan struct{}, maxConcurrentRequests)
+	}
+	return transport
+}
+
+func (t *throttledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	throttled := false
+
+	if t.limiter != nil {
+		reservation := t.limiter.Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			throttled = true
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-req.Context().Done():
+				timer.Stop()
+				reservation.Cancel()
+				return nil, req.Context().Err()
+			}
+		}
+	}
+
+	if t.inFlight != nil {
+		select {
+		case t.inFlight <- struct{}{}:
+		default:
+			throttled = true
+			select {
+			case t.inFlight <- struct{}{}:
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+		inFlightRequests.Add(t.instance, 1)
+		defer func() {
+			inFlightRequests.Add(t.instance, -1)
+			<-t.inFlight
+		}()
+	}
+
+	if throttled {
+		waited := time.Since(start)
+		throttledRequests.Add(t.instance, 1)
+		throttledWaitMillis.Add(t.instance, waited.Milliseconds())
+		log.Trace().Str("instance", t.instance).Str("url", req.URL.String()).Dur("waited", waited).Msg("Throttled Splunk request")
+	}
+	return t.next.RoundTrip(req)
+}
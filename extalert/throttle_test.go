@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"context"
+	"expvar"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func throttledRequestCount(instance string) int64 {
+	if count, ok := throttledRequests.Get(instance).(*expvar.Int); ok {
+		return count.Value()
+	}
+	return 0
+}
+
+func TestThrottledTransport_LimitsRequestRate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	client := &http.Client{Transport: newThrottledTransport(http.DefaultTransport, "rate-limited", 50, 1, 0)}
+
+	throttledBefore := throttledRequestCount("rate-limited")
+	start := time.Now()
+	for range 3 {
+		res, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+	}
+
+	require.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+	require.Equal(t, int64(2), throttledRequestCount("rate-limited")-throttledBefore)
+}
+
+func TestThrottledTransport_LimitsConcurrentRequests(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			observed := maxInFlight.Load()
+			if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: newThrottledTransport(http.DefaultTransport, "concurrency-limited", 0, 0, 2)}
+
+	throttledBefore := throttledRequestCount("concurrency-limited")
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Go(func() {
+			res, err := client.Get(srv.URL)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+		})
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(2), maxInFlight.Load())
+	require.Greater(t, throttledRequestCount("concurrency-limited"), throttledBefore)
+}
+
+func TestThrottledTransport_StopsWaitingWhenContextIsDone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	client := &http.Client{Transport: newThrottledTransport(http.DefaultTransport, "cancelled", 0.1, 1, 0)}
+
+	res, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	_, err = client.Do(req)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestThrottledTransport_Unlimited(t *testing.T) {
+	require.Same(t, http.DefaultTransport, newThrottledTransport(http.DefaultTransport, "unlimited", 0, 0, 0))
+}
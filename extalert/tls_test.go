@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2025 Steadybit GmbH
+
+package extalert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/steadybit/extension-splunk-platform/config"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeSelfSignedClientCertificate(t *testing.T) (string, string, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "extension"},
+		NotBefore:    time.Now().Add(-1 * time.Hour),
+		NotAfter:     time.Now().Add(1 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(t.TempDir(), "client.crt")
+	keyFile := filepath.Join(t.TempDir(), "client.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return certFile, keyFile, certificate
+}
+
+func TestNewSplunkClient_MutualTLS(t *testing.T) {
+	certFile, keyFile, clientCertificate := writeSelfSignedClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCertificate)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"paging":{"total":0},"entry":[]}`))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600))
+
+	t.Run("with client certificate", func(t *testing.T) {
+		c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AuthMode: config.AuthModeToken, AccessToken: "token", ClientCertificateFile: certFile, ClientKeyFile: keyFile, CaCertificateFile: caFile})
+		require.NoError(t, err)
+
+		_, err = c.Alerts(t.Context())
+		require.NoError(t, err)
+	})
+
+	t.Run("without client certificate", func(t *testing.T) {
+		c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AuthMode: config.AuthModeToken, AccessToken: "token", CaCertificateFile: caFile})
+		require.NoError(t, err)
+
+		_, err = c.Alerts(t.Context())
+		require.Error(t, err)
+	})
+
+	t.Run("without CA certificate", func(t *testing.T) {
+		c, err := NewSplunkClient(config.Instance{ApiBaseUrl: srv.URL, AuthMode: config.AuthModeToken, AccessToken: "token", ClientCertificateFile: certFile, ClientKeyFile: keyFile})
+		require.NoError(t, err)
+
+		_, err = c.Alerts(t.Context())
+		require.ErrorContains(t, err, "certificate")
+	})
+}
@@ -4,8 +4,10 @@
 package main
 
 import (
+	"context"
 	_ "github.com/KimMachineGun/automemlimit" // By default, it sets `GOMEMLIMIT` to 90% of cgroup's memory limit.
 	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/action-kit/go/action_kit_sdk"
 	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
@@ -32,15 +34,29 @@ func main() {
 
 	config.ParseConfiguration()
 
-	splunkClient := extalert.NewSplunkClient()
-	discovery_kit_sdk.Register(extalert.NewAlertDiscovery(splunkClient))
-	action_kit_sdk.RegisterAction(extalert.NewAlertCheckAction(splunkClient))
+	splunkClients, err := extalert.NewSplunkClients(config.Config.Instances)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create the Splunk clients.")
+	}
+	discovery_kit_sdk.Register(extalert.NewAlertDiscovery(splunkClients.Instances(), extalert.Resolver[extalert.AlertClient](splunkClients)))
+	discovery_kit_sdk.Register(extalert.NewIndexDiscovery(splunkClients.Instances(), extalert.Resolver[extalert.IndexClient](splunkClients)))
+	discovery_kit_sdk.Register(extalert.NewSavedSearchDiscovery(splunkClients.Instances(), extalert.Resolver[extalert.SavedSearchClient](splunkClients)))
+	action_kit_sdk.RegisterAction(extalert.NewAlertCheckAction(extalert.Resolver[extalert.AlertCheckClient](splunkClients)))
+	action_kit_sdk.RegisterAction(extalert.NewAggregateAlertCheckAction(splunkClients.Instances(), extalert.Resolver[extalert.AggregateAlertClient](splunkClients)))
+	action_kit_sdk.RegisterAction(extalert.NewSearchCheckAction(extalert.Resolver[extalert.SearchClient](splunkClients)))
+	action_kit_sdk.RegisterAction(extalert.NewStreamCheckAction(extalert.Resolver[extalert.StreamClient](splunkClients)))
+	action_kit_sdk.RegisterAction(extalert.NewFreshnessCheckAction(extalert.Resolver[extalert.SearchClient](splunkClients)))
+	action_kit_sdk.RegisterAction(extalert.NewSavedSearchCheckAction(extalert.Resolver[extalert.SavedSearchDispatchClient](splunkClients)))
+	action_kit_sdk.RegisterAction(extalert.NewDisableAlertAction(extalert.Resolver[extalert.AlertDisableClient](splunkClients)))
 
 	exthttp.RegisterRevisionedHandler("/", getExtensionList)
 
 	extsignals.ActivateSignalHandlers()
 	action_kit_sdk.RegisterCoverageEndpoints()
-	exthealth.SetReady(true)
+
+	healthMonitor := extalert.NewHealthMonitor(splunkClients.Instances(), extalert.Resolver[extalert.HealthClient](splunkClients), config.Config.HealthCheckFailureThreshold)
+	healthMonitor.SelfTest(context.Background())
+	healthMonitor.Start(context.Background(), config.Config.HealthCheckInterval)
 
 	exthttp.Listen(exthttp.ListenOpts{
 		Port: 8083,